@@ -1,34 +1,49 @@
 package main
 
 import (
-	"fmt"
+	"context"
 	"math/rand"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/mfojtik/goodmod/pkg/cmd/bump"
+	"github.com/mfojtik/goodmod/pkg/cmd/export"
+	"github.com/mfojtik/goodmod/pkg/cmd/link"
+	"github.com/mfojtik/goodmod/pkg/cmd/prune"
 	"github.com/mfojtik/goodmod/pkg/cmd/replace"
 	"github.com/mfojtik/goodmod/pkg/cmd/report"
+	"github.com/mfojtik/goodmod/pkg/cmd/syncfrom"
+	"github.com/mfojtik/goodmod/pkg/log"
 )
 
 func main() {
 	rand.Seed(time.Now().UTC().UnixNano())
 
-	command := NewMainCommand()
+	command := NewMainCommand(signalContext())
 	if err := command.Execute(); err != nil {
-		if _, printErr := fmt.Fprintf(os.Stderr, "%v\n", err); printErr != nil {
-			panic(printErr)
-		}
+		log.Errorf("%v", err)
 		os.Exit(1)
 	}
 }
 
-func NewMainCommand() *cobra.Command {
+func NewMainCommand(ctx context.Context) *cobra.Command {
+	var (
+		verbosity int
+		logFormat string
+	)
 	cmd := &cobra.Command{
 		Use:   "goodmod",
 		Short: "A pocket knife tool for manipulating go.mod files",
+		// errors are reported by main, the usage is printed only when flags can't be parsed
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return log.Configure(verbosity, logFormat)
+		},
 		Run: func(cmd *cobra.Command, args []string) {
 			if err := cmd.Help(); err != nil {
 				panic(err)
@@ -37,9 +52,33 @@ func NewMainCommand() *cobra.Command {
 		},
 	}
 
-	cmd.AddCommand(replace.NewReplaceCommand())
-	cmd.AddCommand(report.NewReportCommand())
-	cmd.AddCommand(bump.NewBumpCommand())
+	cmd.PersistentFlags().CountVarP(&verbosity, "verbose", "v", "Print more information about progress (repeat for debug and trace messages, eg. -vv)")
+	cmd.PersistentFlags().StringVar(&logFormat, "log-format", log.TextFormat, "Specify format of messages printed to standard error output ('text' or 'json')")
+
+	cmd.AddCommand(replace.NewReplaceCommand(ctx))
+	cmd.AddCommand(report.NewReportCommand(ctx))
+	cmd.AddCommand(bump.NewBumpCommand(ctx))
+	cmd.AddCommand(syncfrom.NewSyncFromCommand(ctx))
+	cmd.AddCommand(prune.NewPruneCommand())
+	cmd.AddCommand(export.NewExportCommand())
+	cmd.AddCommand(link.NewLinkCommand())
+	cmd.AddCommand(link.NewUnlinkCommand(ctx))
 
 	return cmd
 }
+
+// signalContext return context canceled on the first interrupt, so commands can stop resolving and clean up. The second
+// interrupt terminates the process immediately.
+func signalContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		log.Errorf("Interrupted, cancelling (interrupt again to exit immediately) ...")
+		cancel()
+		<-signals
+		os.Exit(130)
+	}()
+	return ctx
+}
@@ -0,0 +1,107 @@
+package bump
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mfojtik/goodmod/pkg/goodmod"
+	"github.com/mfojtik/goodmod/pkg/log"
+)
+
+// pinCommit replaces the module path to point to given commit and refresh the vendor directory. The quiet pin does not
+// print the go commands and the summary of the replace.
+func (opts *Options) pinCommit(ctx context.Context, modulePath string, sha string, quiet bool) error {
+	planner := &goodmod.Planner{
+		Options: goodmod.Options{
+			Commit:           sha,
+			Paths:            []string{modulePath},
+			GoModPath:        opts.GoModPath,
+			GithubClient:     opts.GithubClient,
+			GithubHosts:      opts.githubHosts,
+			ResolverTimeouts: opts.resolverTimeouts,
+			GitAuth:          opts.gitAuth,
+			Chain:            opts.chain,
+		},
+		IgnoreConfig: true,
+	}
+	if _, err := opts.replace(ctx, planner, "", true, quiet); err != nil {
+		return err
+	}
+	return opts.updateVendor(ctx)
+}
+
+// bisect finds the first upstream commit between the old and the new version that makes the verify commands fail.
+// When PinLastGood is set, the module is pinned to the commit right before the first bad commit and the list of commits
+// included in the bump is returned.
+func (opts *Options) bisect(ctx context.Context, modulePath string, verifyErr error) ([]string, error) {
+	log.Infof("Verification failed, bisecting %q commits from %s to %s ...", modulePath, versionToCommit(opts.oldVersion), versionToCommit(opts.newVersion))
+	commits, err := ListCommitRange(ctx, modulePath, versionToCommit(opts.oldVersion), versionToCommit(opts.newVersion), opts.githubHosts)
+	if err != nil {
+		return nil, fmt.Errorf("%v (unable to list commits to bisect: %v)", verifyErr, err)
+	}
+	if len(commits) == 0 {
+		return nil, verifyErr
+	}
+
+	bad, err := firstBadCommit(ctx, commits, func(ctx context.Context, c upstreamCommit) (bool, error) {
+		if err := opts.pinCommit(ctx, modulePath, c.SHA, true); err != nil {
+			return false, err
+		}
+		if err := verify(ctx, opts.verifyCommands); err == context.Canceled || err == context.DeadlineExceeded {
+			return false, err
+		} else if err != nil {
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	firstBad := commits[bad]
+	log.Infof("First upstream commit that breaks verification: %s (%s)", firstBad, firstBad.Commit.String())
+
+	if !opts.PinLastGood {
+		return nil, fmt.Errorf("%v\nfirst bad upstream commit: %s", verifyErr, firstBad)
+	}
+	if bad == 0 {
+		return nil, fmt.Errorf("%v\nfirst bad upstream commit %s directly follows the current version, nothing to bump", verifyErr, firstBad)
+	}
+	lastGood := commits[bad-1]
+	log.Infof("Pinning %q to last good commit %s ...", modulePath, lastGood)
+	if err := opts.pinCommit(ctx, modulePath, lastGood.SHA, false); err != nil {
+		return nil, err
+	}
+	if err := verify(ctx, opts.verifyCommands); err != nil {
+		return nil, fmt.Errorf("last good commit %s failed verification: %v", lastGood, err)
+	}
+
+	result := []string{}
+	for _, c := range commits[0:bad] {
+		if strings.HasPrefix(c.message, "Merge pull request") {
+			continue
+		}
+		result = append(result, c.String())
+	}
+	return result, nil
+}
+
+// firstBadCommit return the index of the first commit that does not pass, using binary search. The last commit is the
+// new version which we know is broken, it is not checked. The error of the check stops the bisect.
+func firstBadCommit(ctx context.Context, commits []upstreamCommit, passes func(context.Context, upstreamCommit) (bool, error)) (int, error) {
+	good, bad := 0, len(commits)-1
+	for good < bad {
+		middle := (good + bad) / 2
+		log.Infof("Bisecting %d commits, trying %s ...", bad-good+1, commits[middle])
+		ok, err := passes(ctx, commits[middle])
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			good = middle + 1
+		} else {
+			bad = middle
+		}
+	}
+	return bad, nil
+}
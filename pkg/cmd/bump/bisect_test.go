@@ -0,0 +1,72 @@
+package bump
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/mfojtik/goodmod/pkg/resolve/types"
+)
+
+// testCommits return n upstream commits in chronological order.
+func testCommits(n int) []upstreamCommit {
+	commits := []upstreamCommit{}
+	for i := 0; i < n; i++ {
+		commits = append(commits, upstreamCommit{Commit: types.Commit{SHA: fmt.Sprintf("%040d", i)}, message: fmt.Sprintf("commit %d", i)})
+	}
+	return commits
+}
+
+func TestFirstBadCommit(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		commits  int
+		firstBad int
+		// tried are the commits verified, in order
+		tried []int
+	}{
+		{name: "single commit is not verified", commits: 1, firstBad: 0, tried: []int{}},
+		{name: "first commit breaks", commits: 2, firstBad: 0, tried: []int{0}},
+		{name: "last commit breaks", commits: 2, firstBad: 1, tried: []int{0}},
+		{name: "narrow to lower half", commits: 8, firstBad: 2, tried: []int{3, 1, 2}},
+		{name: "narrow to upper half", commits: 8, firstBad: 6, tried: []int{3, 5, 6}},
+		{name: "only new version breaks", commits: 8, firstBad: 7, tried: []int{3, 5, 6}},
+		{name: "odd number of commits", commits: 5, firstBad: 1, tried: []int{2, 1, 0}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			commits := testCommits(tc.commits)
+			tried := []int{}
+			// the fake verifier pass commits before the first bad commit
+			bad, err := firstBadCommit(context.TODO(), commits, func(_ context.Context, c upstreamCommit) (bool, error) {
+				for i := range commits {
+					if commits[i].SHA == c.SHA {
+						tried = append(tried, i)
+						return i < tc.firstBad, nil
+					}
+				}
+				return false, fmt.Errorf("unknown commit %s", c)
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bad != tc.firstBad {
+				t.Errorf("expected first bad commit %d, got %d", tc.firstBad, bad)
+			}
+			if !reflect.DeepEqual(tried, tc.tried) {
+				t.Errorf("expected commits %v tried, got %v", tc.tried, tried)
+			}
+		})
+	}
+}
+
+func TestFirstBadCommitError(t *testing.T) {
+	calls := 0
+	_, err := firstBadCommit(context.TODO(), testCommits(8), func(context.Context, upstreamCommit) (bool, error) {
+		calls++
+		return false, context.DeadlineExceeded
+	})
+	if err != context.DeadlineExceeded || calls != 1 {
+		t.Errorf("expected bisect stopped by deadline after first commit, got %v after %d commits", err, calls)
+	}
+}
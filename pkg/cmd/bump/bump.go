@@ -1,20 +1,32 @@
 package bump
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 
 	"github.com/mfojtik/goodmod/pkg/cmd/replace"
+	"github.com/mfojtik/goodmod/pkg/config"
+	"github.com/mfojtik/goodmod/pkg/goodmod"
+	"github.com/mfojtik/goodmod/pkg/log"
+	"github.com/mfojtik/goodmod/pkg/resolve"
 )
 
 var example = `
 # Update 'github.com/openshift/library-go' dependency and commit result
 goodmod bump github.com/openshift/library-go
+
+# Show what would be committed without modifying go.mod or git repository
+goodmod bump github.com/openshift/library-go --dry-run
+
+# Bump to the last upstream commit that pass the verify commands from goodmod.yaml
+goodmod bump github.com/openshift/library-go --bisect --pin-last-good
 `
 
 type Options struct {
@@ -25,34 +37,55 @@ type Options struct {
 	oldVersion string
 	newVersion string
 
-	Verbose      bool
-	GoModPath    string
-	GithubClient *http.Client
+	verifyCommands []string
+
+	DryRun      bool
+	Bisect      bool
+	PinLastGood bool
+	// IncrementalVendor refresh only vendored packages of changed modules instead of running 'go mod tidy' and 'go mod vendor'
+	IncrementalVendor bool
+	GoModPath         string
+	GithubClient      *http.Client
+	// Timeout limit the duration of the whole bump, including verify commands
+	Timeout time.Duration
+
+	resolverTimeouts map[string]time.Duration
+	gitAuth          *resolve.GitAuth
+	githubHosts      *resolve.GithubHosts
+	// chain replace the resolvers of modules when set
+	chain goodmod.ChainFunc
 }
 
 func (opts *Options) AddFlags(flags *pflag.FlagSet) {
-	flags.BoolVar(&opts.Verbose, "verbose", false, "Print more information about progress")
+	flags.BoolVar(&opts.DryRun, "dry-run", false, "Only show what would be committed without modifying go.mod or committing")
+	flags.BoolVar(&opts.Bisect, "bisect", false, "When verification fails, bisect upstream commits to find the first commit that breaks verification")
+	flags.BoolVar(&opts.PinLastGood, "pin-last-good", false, "When bisecting, pin to the last upstream commit that pass verification")
+	flags.BoolVar(&opts.IncrementalVendor, "incremental-vendor", false, "Refresh only vendored packages of changed modules and go.sum instead of running 'go mod tidy' and 'go mod vendor'")
 	flags.StringVar(&opts.ConfigPath, "config", "goodmod.yaml", "Specify file to read the replace rules from")
 	flags.StringVar(&opts.GoModPath, "gomod-file-path", "go.mod", "Specify the path to go.mod file")
+	flags.DurationVar(&opts.Timeout, "timeout", 0, "Specify maximum duration of the bump including verify commands (eg. '30m', zero means no timeout)")
 }
 
-func NewBumpCommand() *cobra.Command {
+func NewBumpCommand(ctx context.Context) *cobra.Command {
 	o := &Options{}
 
 	cmd := &cobra.Command{
 		Use:     "bump [path]",
 		Example: example,
 		Short:   "Bump specified path to latest version",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := o.Complete(args); err != nil {
-				reportFatal("complete failed: %v", err)
+				return fmt.Errorf("complete failed: %v", err)
 			}
 			if err := o.Validate(); err != nil {
-				reportFatal("validate failed: %v", err)
+				return fmt.Errorf("validate failed: %v", err)
 			}
-			if err := o.Run(cmd, args); err != nil {
-				reportFatal("run failed: %v", err)
+			ctx, cancel := resolve.WithTimeout(ctx, o.Timeout)
+			defer cancel()
+			if err := o.Run(ctx, args); err != nil {
+				return fmt.Errorf("run failed: %v", err)
 			}
+			return nil
 		},
 	}
 
@@ -65,45 +98,140 @@ func (opts *Options) Complete(args []string) error {
 	if len(args) > 0 {
 		opts.Path = args[0]
 	}
-	return nil
+	c, err := config.ReadConfig(opts.ConfigPath)
+	if err != nil && err != config.NotFoundError {
+		return err
+	}
+	gitAuthRules, githubHosts := []config.GitAuthRule{}, []config.GithubHost{}
+	if c != nil {
+		opts.verifyCommands = c.Verify
+		opts.resolverTimeouts = c.ResolverTimeouts
+		gitAuthRules, githubHosts = c.GitAuth, c.GithubHosts
+	}
+	if opts.gitAuth, err = resolve.NewGitAuth(gitAuthRules); err != nil {
+		return err
+	}
+	opts.GithubClient = resolve.NewGithubClient(os.Getenv("GITHUB_TOKEN"), resolve.DefaultRetries)
+	opts.githubHosts, err = resolve.NewGithubHosts(opts.GithubClient, githubHosts, resolve.DefaultRetries)
+	return err
 }
 
 func (opts *Options) Validate() error {
 	if len(opts.Path) == 0 {
 		return fmt.Errorf("path argument must be specified")
 	}
+	if opts.PinLastGood && !opts.Bisect {
+		return fmt.Errorf("--pin-last-good requires --bisect")
+	}
+	if opts.Bisect && len(opts.verifyCommands) == 0 {
+		return fmt.Errorf("--bisect requires verify commands in %q", opts.ConfigPath)
+	}
 	return nil
 }
 
-func (opts *Options) runReplace(cmd *cobra.Command, args []string) error {
-	replaceOpts := &replace.Options{
+func (opts *Options) runReplace(ctx context.Context, args []string) error {
+	planner := &goodmod.Planner{Options: goodmod.Options{
 		ConfigPath:   opts.ConfigPath,
 		GoModPath:    opts.GoModPath,
-		Verbose:      opts.Verbose,
-		ApplyReplace: true,
+		Retries:      resolve.DefaultRetries,
+		GitAuth:      opts.gitAuth,
+		GithubClient: opts.GithubClient,
+		GithubHosts:  opts.githubHosts,
+		Chain:        opts.chain,
+	}}
+	change, err := opts.replace(ctx, planner, args[0], !opts.DryRun, false)
+	if err != nil {
+		return err
+	}
+
+	// inherit data we gathered in replace
+	opts.oldVersion, opts.newVersion = change.OldVersion, change.NewVersion
+	return nil
+}
+
+// replace plan the change of the module and apply it, the go commands applying the change are printed. The error is
+// returned when the module failed to resolve. The quiet replace does not print the commands nor the summary, it is used
+// for the intermediate steps (eg. bisect pins).
+func (opts *Options) replace(ctx context.Context, planner *goodmod.Planner, modulePath string, apply, quiet bool) (goodmod.Change, error) {
+	plan, err := planner.Plan(ctx, modulePath)
+	if err != nil {
+		return goodmod.Change{}, err
+	}
+	failed := plan.Failed()
+	applied := len(failed) == 0
+	if applied && !quiet {
+		for _, command := range plan.Commands() {
+			if _, err := fmt.Fprintln(os.Stdout, command); err != nil {
+				return goodmod.Change{}, err
+			}
+		}
+	}
+	if applied && apply {
+		applier := &goodmod.Applier{UpdateGoSum: opts.IncrementalVendor, Vendor: opts.IncrementalVendor}
+		if err := applier.Apply(ctx, plan); err != nil {
+			return goodmod.Change{}, err
+		}
 	}
-	replaceOpts.RunCommand(cmd, args)
+	if quiet {
+		if !applied {
+			return goodmod.Change{}, fmt.Errorf("failed to replace %s: %s", failed[0].OldPath, failed[0].Failure)
+		}
+	} else if err := replace.Summarize(plan.Changes, applied); err != nil {
+		return goodmod.Change{}, err
+	}
+	change, _ := plan.Change(modulePath)
+	return change, nil
+}
 
-	// inherit data we gathered in replace command
-	opts.oldVersion, opts.newVersion = replaceOpts.GetVersionsForPath(args[0])
-	opts.GithubClient = replaceOpts.GithubClient
+// Run performs the bump transactionally. The go.mod, go.sum and vendor state is recorded before the replace is applied
+// and when any of the steps fail, the state is restored and partial commits are dropped.
+func (opts *Options) Run(ctx context.Context, args []string) error {
+	if opts.DryRun {
+		return opts.bump(ctx, args)
+	}
+	s, err := takeSnapshot(opts.GoModPath)
+	if err != nil {
+		return err
+	}
+	defer s.cleanup()
+	if err := opts.bump(ctx, args); err != nil {
+		log.Infof("Bump failed, restoring go.mod, go.sum and vendor to %s ...", s.head)
+		if restoreErr := s.restore(); restoreErr != nil {
+			return fmt.Errorf("%v (rollback failed: %v)", err, restoreErr)
+		}
+		return err
+	}
 	return nil
 }
 
-func (opts *Options) Run(cmd *cobra.Command, args []string) error {
-	if err := opts.runReplace(cmd, args); err != nil {
+func (opts *Options) bump(ctx context.Context, args []string) error {
+	if err := opts.runReplace(ctx, args); err != nil {
 		return err
 	}
 	if len(opts.oldVersion) == 0 || len(opts.newVersion) == 0 {
 		return fmt.Errorf("path %q old version (%q) or new version (%q) is empty", args[0], opts.oldVersion, opts.newVersion)
 	}
-	reportVerbose("Listing %q commits from %s to %s", args[0], versionToCommit(opts.oldVersion), versionToCommit(opts.newVersion))
-	commits, err := ListCommits(args[0], versionToCommit(opts.oldVersion), versionToCommit(opts.newVersion), opts.GithubClient)
+	log.Infof("Listing %q commits from %s to %s", args[0], versionToCommit(opts.oldVersion), versionToCommit(opts.newVersion))
+	commits, err := ListCommits(ctx, args[0], versionToCommit(opts.oldVersion), versionToCommit(opts.newVersion), opts.githubHosts)
 	if err != nil {
 		return err
 	}
 	for _, c := range commits {
-		reportVerbose("%s", c)
+		log.Infof("%s", c)
+	}
+	if opts.DryRun {
+		return printDryRun(commits)
+	}
+	if err := opts.updateVendor(ctx); err != nil {
+		return err
+	}
+	if err := verify(ctx, opts.verifyCommands); err != nil {
+		if !opts.Bisect {
+			return err
+		}
+		if commits, err = opts.bisect(ctx, args[0], err); err != nil {
+			return err
+		}
 	}
 	if err := commitGoMod(); err != nil {
 		return err
@@ -114,28 +242,14 @@ func (opts *Options) Run(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func printDryRun(commits []string) error {
+	_, err := fmt.Fprintf(os.Stdout, "# Would commit go.mod with message:\n%s\n\n# Would run 'go mod tidy', 'go mod vendor', verify commands and commit go.sum and vendor with message:\n%s\n",
+		goModCommitMessage, vendorCommitMessage(commits))
+	return err
+}
+
 func versionToCommit(version string) string {
 	parts := strings.Split(version, "-")
 	lastPart := parts[len(parts)-1]
 	return lastPart
 }
-
-func reportVerbose(message string, objects ...interface{}) {
-	if _, err := fmt.Fprintf(os.Stderr, "# "+message+"\n", objects...); err != nil {
-		panic(err)
-	}
-}
-
-func reportFatal(message interface{}, objects ...interface{}) {
-	formatMessage := ""
-	switch v := message.(type) {
-	case error:
-		formatMessage = v.Error()
-	case string:
-		formatMessage = v
-	}
-	if _, err := fmt.Fprintf(os.Stderr, "ERROR: "+formatMessage+"\n", objects...); err != nil {
-		panic(err)
-	}
-	os.Exit(1)
-}
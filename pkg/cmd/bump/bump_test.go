@@ -0,0 +1,97 @@
+package bump
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mfojtik/goodmod/pkg/config"
+	"github.com/mfojtik/goodmod/pkg/resolve"
+	"github.com/mfojtik/goodmod/pkg/resolve/types"
+)
+
+const testGoMod = `module example.com/test
+
+go 1.13
+
+require github.corp.example.com/team/api v0.0.0-20191016115129-c07a134afb42
+
+replace github.corp.example.com/team/api => github.corp.example.com/team/api v0.0.0-20191016115129-c07a134afb42
+`
+
+// fakeResolver resolve every ref of every module to the commit.
+type fakeResolver struct {
+	commit *types.Commit
+}
+
+func (r *fakeResolver) Resolve(context.Context, string, string) (*types.Commit, error) {
+	return r.commit, nil
+}
+
+// testHosts return the hosts with 'github.corp.example.com' GitHub Enterprise Server served by the handler.
+func testHosts(t *testing.T, handler http.Handler) (*resolve.GithubHosts, func()) {
+	server := httptest.NewServer(handler)
+	hosts, err := resolve.NewGithubHosts(nil, []config.GithubHost{
+		{Host: "github.corp.example.com", APIURL: server.URL + "/api/v3/", UploadURL: server.URL + "/api/uploads/"},
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hosts, server.Close
+}
+
+func TestDryRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bump-dry-run")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	goModPath := filepath.Join(dir, "go.mod")
+	writeFile(t, goModPath, testGoMod)
+	configPath := filepath.Join(dir, "goodmod.yaml")
+	writeFile(t, configPath, "rules:\n- paths:\n  - github.corp.example.com/team/api\n  branch: master\n")
+
+	hosts, closeServer := testHosts(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/repos/team/api/commits" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `[{"sha": "3d4e2ac1b2ad0e0a5a6b9c14a0b9a1ec8b41a5e4", "commit": {"message": "Add API"}}, {"sha": "c07a134afb4217bd3dc3cd89a6e5eb5b1a2c4b6e", "commit": {"message": "Initial"}}]`)
+	}))
+	defer closeServer()
+
+	c := &types.Commit{SHA: "3d4e2ac1b2ad0e0a5a6b9c14a0b9a1ec8b41a5e4", Timestamp: time.Date(2019, 11, 5, 13, 14, 21, 0, time.UTC)}
+	o := &Options{
+		Path:        "github.corp.example.com/team/api",
+		ConfigPath:  configPath,
+		GoModPath:   goModPath,
+		DryRun:      true,
+		githubHosts: hosts,
+		chain: func(kind resolve.RefKind) ([]resolve.ModulerResolver, error) {
+			return []resolve.ModulerResolver{&fakeResolver{commit: c}}, nil
+		},
+	}
+	// the dry run does not take a snapshot, so it runs outside of git repository
+	if err := o.Run(context.TODO(), []string{o.Path}); err != nil {
+		t.Fatal(err)
+	}
+	if o.oldVersion != "v0.0.0-20191016115129-c07a134afb42" || o.newVersion != c.String() {
+		t.Errorf("unexpected versions %q => %q", o.oldVersion, o.newVersion)
+	}
+	content, err := ioutil.ReadFile(goModPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != testGoMod {
+		t.Errorf("expected go.mod unchanged, got:\n%s", content)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "go.sum")); !os.IsNotExist(err) {
+		t.Errorf("expected go.sum not created, got %v", err)
+	}
+}
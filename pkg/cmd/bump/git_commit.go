@@ -1,36 +1,51 @@
 package bump
 
 import (
+	"context"
 	"fmt"
 	"os/exec"
 	"strings"
 )
 
+const goModCommitMessage = "bump(*): go.mod changes"
+
+func vendorCommitMessage(commits []string) string {
+	messages := []string{"bump(*): go mod vendor", ""}
+	messages = append(messages, commits...)
+	return strings.Join(messages, "\n")
+}
+
 func commitGoMod() error {
 	if out, err := exec.Command("git", "add", "go.mod").CombinedOutput(); err != nil {
 		return fmt.Errorf("%s", out)
 	}
-	if out, err := exec.Command("git", "commit", "-m", "bump(*): go.mod changes").CombinedOutput(); err != nil {
+	if out, err := exec.Command("git", "commit", "-m", goModCommitMessage).CombinedOutput(); err != nil {
 		return fmt.Errorf("%s", out)
 
 	}
 	return nil
 }
 
-func commitVendor(commits []string) error {
-	messages := []string{"bump(*): go mod vendor", ""}
-	messages = append(messages, commits...)
-
-	if out, err := exec.Command("go", "mod", "tidy").CombinedOutput(); err != nil {
+// updateVendor runs 'go mod tidy' and 'go mod vendor'. With incremental vendor, the replace command already refreshed
+// the vendored packages and go.sum.
+func (opts *Options) updateVendor(ctx context.Context) error {
+	if opts.IncrementalVendor {
+		return nil
+	}
+	if out, err := exec.CommandContext(ctx, "go", "mod", "tidy").CombinedOutput(); err != nil {
 		return fmt.Errorf("%s", out)
 	}
-	if out, err := exec.Command("go", "mod", "vendor").CombinedOutput(); err != nil {
+	if out, err := exec.CommandContext(ctx, "go", "mod", "vendor").CombinedOutput(); err != nil {
 		return fmt.Errorf("%s", out)
 	}
+	return nil
+}
+
+func commitVendor(commits []string) error {
 	if out, err := exec.Command("git", "add", "go.sum", "./vendor").CombinedOutput(); err != nil {
 		return fmt.Errorf("%s", out)
 	}
-	if out, err := exec.Command("git", "commit", "-m", fmt.Sprintf("%s", strings.Join(messages, "\n"))).CombinedOutput(); err != nil {
+	if out, err := exec.Command("git", "commit", "-m", vendorCommitMessage(commits)).CombinedOutput(); err != nil {
 		return fmt.Errorf("%s", out)
 	}
 	return nil
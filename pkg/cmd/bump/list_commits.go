@@ -3,12 +3,12 @@ package bump
 import (
 	"context"
 	"fmt"
-	"net/http"
 	"strings"
 
 	"github.com/google/go-github/v28/github"
 
 	"github.com/mfojtik/goodmod/pkg/resolve"
+	"github.com/mfojtik/goodmod/pkg/resolve/types"
 )
 
 func sanitizeCommitMessage(message string) string {
@@ -16,10 +16,13 @@ func sanitizeCommitMessage(message string) string {
 	return strings.TrimSpace(firstLine)
 }
 
-func ListCommits(modulePath string, fromCommit, toCommit string, oauthClient *http.Client) ([]string, error) {
-	client := github.NewClient(oauthClient)
+func ListCommits(ctx context.Context, modulePath string, fromCommit, toCommit string, hosts *resolve.GithubHosts) ([]string, error) {
+	client, err := hosts.APIClient(modulePath)
+	if err != nil {
+		return nil, err
+	}
 	owner, repo := resolve.GetGithubOwnerAndRepo(resolve.RepositoryModulePath(modulePath))
-	commits, _, err := client.Repositories.ListCommits(context.TODO(), owner, repo, &github.CommitsListOptions{
+	commits, _, err := client.Repositories.ListCommits(ctx, owner, repo, &github.CommitsListOptions{
 		SHA: fromCommit,
 	})
 	if err != nil {
@@ -37,3 +40,69 @@ func ListCommits(modulePath string, fromCommit, toCommit string, oauthClient *ht
 	}
 	return result, nil
 }
+
+type upstreamCommit struct {
+	types.Commit
+	message string
+}
+
+func (c upstreamCommit) String() string {
+	return fmt.Sprintf("%s: %s", c.SHA[0:8], sanitizeCommitMessage(c.message))
+}
+
+// ListCommitRange list the first-parent commits between fromCommit (excluded) and toCommit (included) in chronological
+// order. The commits of side branches merged in the range are not included, every listed commit was the head of the
+// branch once. The commits are listed page by page until fromCommit is reached.
+func ListCommitRange(ctx context.Context, modulePath string, fromCommit, toCommit string, hosts *resolve.GithubHosts) ([]upstreamCommit, error) {
+	client, err := hosts.APIClient(modulePath)
+	if err != nil {
+		return nil, err
+	}
+	owner, repo := resolve.GetGithubOwnerAndRepo(resolve.RepositoryModulePath(modulePath))
+	listOptions := &github.CommitsListOptions{SHA: toCommit, ListOptions: github.ListOptions{PerPage: 100}}
+	listed := map[string]*github.RepositoryCommit{}
+	// next is the SHA of the next first-parent commit, empty until the toCommit is listed
+	next := ""
+	result := []upstreamCommit{}
+	for {
+		commits, resp, err := client.Repositories.ListCommits(ctx, owner, repo, listOptions)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range commits {
+			listed[c.GetSHA()] = c
+		}
+		if len(next) == 0 && len(commits) > 0 {
+			next = commits[0].GetSHA()
+		}
+		for len(next) > 0 && !strings.HasPrefix(next, fromCommit) {
+			c, ok := listed[next]
+			if !ok {
+				break
+			}
+			result = append(result, upstreamCommit{
+				Commit: types.Commit{
+					SHA:       c.GetSHA(),
+					Timestamp: c.GetCommit().GetCommitter().GetDate(),
+				},
+				message: c.GetCommit().GetMessage(),
+			})
+			next = ""
+			if len(c.Parents) > 0 {
+				next = c.Parents[0].GetSHA()
+			}
+		}
+		if len(next) > 0 && strings.HasPrefix(next, fromCommit) {
+			break
+		}
+		if len(next) == 0 || resp.NextPage == 0 {
+			return nil, fmt.Errorf("commit %s is not a first-parent ancestor of %s", fromCommit, toCommit)
+		}
+		listOptions.Page = resp.NextPage
+	}
+	// listed from the newest
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return result, nil
+}
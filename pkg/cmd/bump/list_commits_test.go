@@ -0,0 +1,62 @@
+package bump
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// commitJSON return the commit as listed by GitHub API, SHAs are made of the repeated name.
+func commitJSON(name string, parents ...string) string {
+	parentsJSON := []string{}
+	for _, p := range parents {
+		parentsJSON = append(parentsJSON, fmt.Sprintf(`{"sha": "%s"}`, strings.Repeat(p, 40)))
+	}
+	return fmt.Sprintf(`{"sha": "%s", "commit": {"message": "commit %s"}, "parents": [%s]}`, strings.Repeat(name, 40), name, strings.Join(parentsJSON, ", "))
+}
+
+func TestListCommitRange(t *testing.T) {
+	// f <- a <- m (merge of side branch s) <- t, s <- f
+	pages := map[string][]string{
+		"":  {commitJSON("t", "m"), commitJSON("m", "a", "s")},
+		"2": {commitJSON("s", "f"), commitJSON("a", "f")},
+		"3": {commitJSON("f", "e"), commitJSON("e")},
+	}
+	requests := 0
+	hosts, closeServer := testHosts(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/api/v3/repos/team/api/commits" || r.URL.Query().Get("sha") != strings.Repeat("t", 12) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		page := r.URL.Query().Get("page")
+		next := map[string]string{"": "2", "2": "3"}[page]
+		if len(next) > 0 {
+			w.Header().Set("Link", fmt.Sprintf(`<http://%s%s?page=%s&sha=%s>; rel="next"`, r.Host, r.URL.Path, next, strings.Repeat("t", 12)))
+		}
+		fmt.Fprintf(w, "[%s]", strings.Join(pages[page], ", "))
+	}))
+	defer closeServer()
+
+	commits, err := ListCommitRange(context.TODO(), "github.corp.example.com/team/api", strings.Repeat("f", 12), strings.Repeat("t", 12), hosts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages := []string{}
+	for _, c := range commits {
+		messages = append(messages, c.message)
+	}
+	if expected := []string{"commit a", "commit m", "commit t"}; !reflect.DeepEqual(messages, expected) {
+		t.Errorf("expected first-parent commits %v, got %v", expected, messages)
+	}
+	if requests != 2 {
+		t.Errorf("expected listing to stop at the page with the from commit, got %d requests", requests)
+	}
+
+	if _, err := ListCommitRange(context.TODO(), "github.corp.example.com/team/api", strings.Repeat("s", 12), strings.Repeat("t", 12), hosts); err == nil || !strings.Contains(err.Error(), "is not a first-parent ancestor") {
+		t.Errorf("expected side branch commit not to be reached, got %v", err)
+	}
+}
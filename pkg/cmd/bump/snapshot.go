@@ -0,0 +1,140 @@
+package bump
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/mfojtik/goodmod/pkg/log"
+)
+
+// snapshot holds the state of go.mod, go.sum and vendor directory before the bump started, so it can be restored when
+// any step of the bump fails.
+type snapshot struct {
+	head string
+
+	// files maps file path to its content, nil content means the file did not exist
+	files map[string][]byte
+
+	vendorDir    string
+	vendorBackup string
+}
+
+func takeSnapshot(goModPath string) (*snapshot, error) {
+	head, err := exec.Command("git", "rev-parse", "HEAD").CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get HEAD commit: %s", head)
+	}
+	dir := filepath.Dir(goModPath)
+	s := &snapshot{
+		head:      strings.TrimSpace(string(head)),
+		files:     map[string][]byte{},
+		vendorDir: filepath.Join(dir, "vendor"),
+	}
+	for _, f := range []string{goModPath, filepath.Join(dir, "go.sum")} {
+		content, err := ioutil.ReadFile(f)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		s.files[f] = content
+	}
+	if _, err := os.Stat(s.vendorDir); err == nil {
+		backup, err := ioutil.TempDir("", "goodmod-vendor-")
+		if err != nil {
+			return nil, err
+		}
+		s.vendorBackup = backup
+		if err := copyDir(s.vendorDir, s.vendorBackup); err != nil {
+			s.cleanup()
+			return nil, fmt.Errorf("unable to backup %q: %v", s.vendorDir, err)
+		}
+	}
+	return s, nil
+}
+
+// restore drops all commits made after the snapshot was taken and restore go.mod, go.sum and vendor directory content.
+func (s *snapshot) restore() error {
+	paths := []string{s.vendorDir}
+	for f := range s.files {
+		paths = append(paths, f)
+	}
+	if out, err := exec.Command("git", "reset", "--soft", s.head).CombinedOutput(); err != nil {
+		return fmt.Errorf("unable to reset to %s: %s", s.head, out)
+	}
+	// unstage everything we might have added to index
+	if out, err := exec.Command("git", append([]string{"reset", "-q", "HEAD", "--"}, paths...)...).CombinedOutput(); err != nil {
+		return fmt.Errorf("unable to unstage changes: %s", out)
+	}
+	for f, content := range s.files {
+		if content == nil {
+			if err := os.Remove(f); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			continue
+		}
+		if err := ioutil.WriteFile(f, content, 0644); err != nil {
+			return err
+		}
+	}
+	if err := os.RemoveAll(s.vendorDir); err != nil {
+		return err
+	}
+	if len(s.vendorBackup) > 0 {
+		return copyDir(s.vendorBackup, s.vendorDir)
+	}
+	return nil
+}
+
+func (s *snapshot) cleanup() {
+	if len(s.vendorBackup) > 0 {
+		if err := os.RemoveAll(s.vendorBackup); err != nil {
+			log.Infof("Unable to remove %q: %v", s.vendorBackup, err)
+		}
+	}
+}
+
+func copyDir(from, to string) error {
+	return filepath.Walk(from, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(from, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(to, relPath)
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(target, info.Mode())
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		default:
+			return copyFile(path, target, info.Mode())
+		}
+	})
+}
+
+func copyFile(from, to string, mode os.FileMode) error {
+	in, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(to, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,113 @@
+package bump
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// gitRepository create repository in the directory using git commands and return function that run git in it.
+func gitRepository(t *testing.T, dir string) func(args ...string) string {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skipf("git is not available: %v", err)
+	}
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	git("init", "-q")
+	return git
+}
+
+func writeFile(t *testing.T, path, content string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// chdir change the working directory to the directory, the bump runs git in the working directory.
+func chdir(t *testing.T, dir string) func() {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	return func() {
+		if err := os.Chdir(wd); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bump-snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	git := gitRepository(t, dir)
+	goModPath := filepath.Join(dir, "go.mod")
+	writeFile(t, goModPath, "module example.com/test\n")
+	writeFile(t, filepath.Join(dir, "vendor", "modules.txt"), "# github.com/openshift/api v0.0.0-20191016115129-c07a134afb42\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+	head := git("rev-parse", "HEAD")
+	defer chdir(t, dir)()
+
+	s, err := takeSnapshot(goModPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.cleanup()
+
+	// the partial bump changed go.mod, created go.sum, changed vendor and committed go.mod
+	writeFile(t, goModPath, "module example.com/test\n\nreplace github.com/openshift/api => github.com/openshift/api v0.0.0-20191105131421-3d4e2ac1b2ad\n")
+	git("commit", "-q", "-am", "bump(*): go.mod changes")
+	writeFile(t, filepath.Join(dir, "go.sum"), "github.com/openshift/api v0.0.0-20191105131421-3d4e2ac1b2ad h1:fake=\n")
+	writeFile(t, filepath.Join(dir, "vendor", "modules.txt"), "# github.com/openshift/api v0.0.0-20191105131421-3d4e2ac1b2ad\n")
+	writeFile(t, filepath.Join(dir, "vendor", "github.com", "openshift", "api", "types.go"), "package api\n")
+	git("add", "go.sum", "vendor")
+
+	if err := s.restore(); err != nil {
+		t.Fatal(err)
+	}
+	if restored := git("rev-parse", "HEAD"); restored != head {
+		t.Errorf("expected HEAD %s, got %s", head, restored)
+	}
+	if status := git("status", "--porcelain"); len(status) > 0 {
+		t.Errorf("expected clean worktree, got:\n%s", status)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "go.sum")); !os.IsNotExist(err) {
+		t.Errorf("expected go.sum removed, got %v", err)
+	}
+}
+
+func TestSnapshotWithoutRepository(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bump-snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer chdir(t, dir)()
+
+	if _, err := takeSnapshot(filepath.Join(dir, "go.mod")); err == nil || !strings.Contains(err.Error(), "unable to get HEAD commit") {
+		t.Errorf("expected HEAD error, got %v", err)
+	}
+}
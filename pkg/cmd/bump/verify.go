@@ -0,0 +1,25 @@
+package bump
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/mfojtik/goodmod/pkg/log"
+)
+
+// verify runs all verify commands and return error for the first command that fails. The running command is killed when
+// the context is canceled.
+func verify(ctx context.Context, commands []string) error {
+	for _, c := range commands {
+		log.Infof("Verifying with %q ...", c)
+		if out, err := exec.CommandContext(ctx, "sh", "-c", c).CombinedOutput(); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("verify command %q failed: %v\n%s", c, err, strings.TrimSpace(string(out)))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package bump
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		commands []string
+		// err are the substrings of the expected error, no error is expected when empty
+		err []string
+	}{
+		{name: "no commands"},
+		{name: "all pass", commands: []string{"true", "exit 0"}},
+		{name: "exit code", commands: []string{"true", "exit 3", "true"}, err: []string{`verify command "exit 3" failed`, "exit status 3"}},
+		{name: "output included", commands: []string{"echo broken build; false"}, err: []string{"exit status 1", "broken build"}},
+		{name: "shell expansion", commands: []string{`test "$(echo ok)" = ok`}},
+		{name: "first failure stops", commands: []string{"exit 2", "exit 3"}, err: []string{"exit status 2"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := verify(context.TODO(), tc.commands)
+			if len(tc.err) == 0 {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected error")
+			}
+			for _, s := range tc.err {
+				if !strings.Contains(err.Error(), s) {
+					t.Errorf("expected %q in error, got %v", s, err)
+				}
+			}
+		})
+	}
+}
+
+func TestVerifyCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	if err := verify(ctx, []string{"sleep 10"}); err != context.Canceled {
+		t.Errorf("expected canceled, got %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+package export
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v2"
+
+	"github.com/mfojtik/goodmod/pkg/config"
+	"github.com/mfojtik/goodmod/pkg/golang"
+)
+
+var example = `
+# Print config file with rules from go.mod annotations (// goodmod: tag=kubernetes-1.16.2) added
+goodmod export --to=yaml
+
+# Write rules from config file as annotations to matching replace and require lines in go.mod
+goodmod export --to=gomod --apply
+`
+
+const (
+	toYAML  = "yaml"
+	toGoMod = "gomod"
+)
+
+type Options struct {
+	ConfigPath string
+	GoModPath  string
+	To         string
+	Apply      bool
+}
+
+func (opts *Options) AddFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&opts.ConfigPath, "config", "goodmod.yaml", "Specify file to read the replace rules from")
+	flags.StringVar(&opts.GoModPath, "gomod-file-path", "go.mod", "Specify the path to go.mod file")
+	flags.StringVar(&opts.To, "to", toYAML, "Specify the form to convert rules to ('yaml' or 'gomod')")
+	flags.BoolVar(&opts.Apply, "apply", false, "Write the result to config file or go.mod file instead of printing it")
+}
+
+func (opts *Options) Validate() error {
+	if opts.To != toYAML && opts.To != toGoMod {
+		return fmt.Errorf("--to must be %q or %q", toYAML, toGoMod)
+	}
+	return nil
+}
+
+func (opts *Options) readModFile() (*golang.ModFile, error) {
+	modBytes, err := ioutil.ReadFile(opts.GoModPath)
+	if err != nil {
+		return nil, err
+	}
+	return golang.ParseModFile(opts.GoModPath, modBytes, nil)
+}
+
+// exportYAML return the config file content with rules from go.mod annotations added after the config file rules.
+func (opts *Options) exportYAML() ([]byte, error) {
+	c, err := config.ReadConfig(opts.ConfigPath)
+	if err == config.NotFoundError {
+		c = &config.Config{}
+	} else if err != nil {
+		return nil, err
+	}
+	f, err := opts.readModFile()
+	if err != nil {
+		return nil, err
+	}
+	annotations, err := config.AnnotationRules(f)
+	if err != nil {
+		return nil, err
+	}
+	c.Rules = config.MergeRules(c.Rules, annotations)
+	return yaml.Marshal(c)
+}
+
+// annotateModFile set annotations on all replace and require lines in go.mod matching a config file rule.
+// Replaced modules are annotated on the replace line only.
+func annotateModFile(f *golang.ModFile, rules []config.Rule) {
+	replaced := map[string]bool{}
+	annotate := func(modulePath string, line *golang.Line) {
+		rule := config.RuleForPath(rules, modulePath)
+		if rule == nil {
+			return
+		}
+		config.SetAnnotation(line, rule)
+	}
+	for _, r := range f.Replace {
+		replaced[r.Old.Path] = true
+		annotate(r.Old.Path, r.Syntax)
+	}
+	for _, r := range f.Require {
+		if !replaced[r.Mod.Path] {
+			annotate(r.Mod.Path, r.Syntax)
+		}
+	}
+}
+
+// exportGoMod return the go.mod file content with config file rules written as annotations.
+func (opts *Options) exportGoMod() ([]byte, error) {
+	c, err := config.ReadConfig(opts.ConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	f, err := opts.readModFile()
+	if err != nil {
+		return nil, err
+	}
+	annotateModFile(f, c.Rules)
+	return f.Format()
+}
+
+func (opts *Options) Run() error {
+	var (
+		out    []byte
+		err    error
+		target string
+	)
+	switch opts.To {
+	case toYAML:
+		out, err = opts.exportYAML()
+		target = opts.ConfigPath
+	case toGoMod:
+		out, err = opts.exportGoMod()
+		target = opts.GoModPath
+	}
+	if err != nil {
+		return err
+	}
+	if opts.Apply {
+		return ioutil.WriteFile(target, out, 0644)
+	}
+	_, err = os.Stdout.Write(out)
+	return err
+}
+
+func NewExportCommand() *cobra.Command {
+	o := &Options{}
+
+	cmd := &cobra.Command{
+		Use:     "export",
+		Example: example,
+		Short:   "Convert rules between config file and go.mod annotations",
+		Long:    "Convert tracking rules between config file and go.mod line annotations (// goodmod: tag=kubernetes-1.16.2)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Validate(); err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	o.AddFlags(cmd.Flags())
+
+	return cmd
+}
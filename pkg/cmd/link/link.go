@@ -0,0 +1,269 @@
+package link
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/mfojtik/goodmod/pkg/cmd/replace"
+	"github.com/mfojtik/goodmod/pkg/config"
+	"github.com/mfojtik/goodmod/pkg/golang"
+	"github.com/mfojtik/goodmod/pkg/goodmod"
+	"github.com/mfojtik/goodmod/pkg/resolve"
+)
+
+var linkExample = `
+# Replace github.com/openshift/library-go with ../library-go checkout
+goodmod link github.com/openshift/library-go
+
+# Replace github.com/openshift/api with checkout in given directory
+goodmod link github.com/openshift/api ~/go/src/github.com/openshift/api
+`
+
+var unlinkExample = `
+# Restore github.com/openshift/library-go to the version it was pinned to before it was linked
+goodmod unlink github.com/openshift/library-go
+
+# Resolve github.com/openshift/library-go using its rule instead of restoring the previous version
+goodmod unlink github.com/openshift/library-go --resolve
+`
+
+type Options struct {
+	ConfigPath string
+	GoModPath  string
+	// Resolve resolve the module using its rule when unlinking, instead of restoring the previous pin
+	Resolve bool
+	// UpdateGoSum update go.sum when the module resolved by its rule is applied
+	UpdateGoSum bool
+
+	modulePath string
+	dir        string
+}
+
+func (opts *Options) AddFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&opts.ConfigPath, "config", "goodmod.yaml", "Specify file to read the replace rules from")
+	flags.StringVar(&opts.GoModPath, "gomod-file-path", "go.mod", "Specify the path to go.mod file")
+}
+
+func (opts *Options) readModFile() (*golang.ModFile, error) {
+	modBytes, err := ioutil.ReadFile(opts.GoModPath)
+	if err != nil {
+		return nil, err
+	}
+	return golang.ParseModFile(opts.GoModPath, modBytes, nil)
+}
+
+func (opts *Options) writeModFile(f *golang.ModFile) error {
+	f.Cleanup()
+	out, err := f.Format()
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(opts.GoModPath, out, 0644)
+}
+
+// moduleReplace return the replace of all versions of the module, nil when the module is not replaced.
+func moduleReplace(f *golang.ModFile, modulePath string) *golang.Replace {
+	for _, r := range f.Replace {
+		if r.Old.Path == modulePath && len(r.Old.Version) == 0 {
+			return r
+		}
+	}
+	return nil
+}
+
+func isRequired(f *golang.ModFile, modulePath string) bool {
+	for _, r := range f.Require {
+		if r.Mod.Path == modulePath {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultDir return the sibling directory of the go.mod directory named after the module repository (eg.
+// '../library-go' for 'github.com/openshift/library-go/v2').
+func defaultDir(modulePath string) string {
+	name := path.Base(modulePath)
+	if len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = path.Base(path.Dir(modulePath))
+	}
+	return "../" + name
+}
+
+// replaceDir return the directory as replace target, relative directories must start with './' or '../'.
+func replaceDir(dir string) string {
+	dir = filepath.ToSlash(filepath.Clean(dir))
+	if golang.IsDirectoryPath(dir) {
+		return dir
+	}
+	return "./" + dir
+}
+
+// checkModuleDir return error when the directory (relative to go.mod directory) is not the module checkout.
+func (opts *Options) checkModuleDir(dir string) error {
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(filepath.Dir(opts.GoModPath), dir)
+	}
+	content, err := ioutil.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return fmt.Errorf("%s is not a module directory: %v", dir, err)
+	}
+	f, err := golang.ParseModFileLax(filepath.Join(dir, "go.mod"), content, nil)
+	if err != nil {
+		return err
+	}
+	if f.Module == nil || f.Module.Mod.Path != opts.modulePath {
+		return fmt.Errorf("%s is not a checkout of %s", dir, opts.modulePath)
+	}
+	return nil
+}
+
+// Link replace the module with the local directory. The version the module was replaced with is kept in the replace
+// line comment, so it can be restored by Unlink.
+func (opts *Options) Link() error {
+	f, err := opts.readModFile()
+	if err != nil {
+		return err
+	}
+	existing := moduleReplace(f, opts.modulePath)
+	if existing == nil && !isRequired(f, opts.modulePath) {
+		return fmt.Errorf("%s is neither required nor replaced in %s", opts.modulePath, opts.GoModPath)
+	}
+	dir := replaceDir(opts.dir)
+	if err := opts.checkModuleDir(dir); err != nil {
+		return err
+	}
+	previous := "the required version"
+	switch {
+	case existing != nil && golang.IsDirectoryPath(existing.New.Path):
+		// relinked to another directory, the pin from the first link stays
+		previous = existing.New.Path
+	case existing != nil:
+		config.SetLinkedPin(existing.Syntax, existing.New.Path, existing.New.Version)
+		previous = existing.New.Path + "@" + existing.New.Version
+	}
+	// the existing replace is updated in place, keeping its comments
+	if err := f.AddReplace(opts.modulePath, "", dir, ""); err != nil {
+		return err
+	}
+	if err := opts.writeModFile(f); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(os.Stdout, "%s: %s => %s (was %s)\n", opts.GoModPath, opts.modulePath, dir, previous)
+	return err
+}
+
+// Unlink replace the module linked to local directory with the version it had before it was linked. When the module
+// had no replace or Resolve is set, the module is resolved using its rule. Modules without rule use the required version.
+func (opts *Options) Unlink(ctx context.Context) error {
+	f, err := opts.readModFile()
+	if err != nil {
+		return err
+	}
+	existing := moduleReplace(f, opts.modulePath)
+	if existing == nil || !golang.IsDirectoryPath(existing.New.Path) {
+		return fmt.Errorf("%s is not linked to local directory in %s", opts.modulePath, opts.GoModPath)
+	}
+	c, err := config.ReadRules(opts.ConfigPath, opts.GoModPath)
+	if err != nil && err != config.NotFoundError {
+		return err
+	}
+	var rule *config.Rule
+	if c != nil {
+		rule = config.RuleForPath(c.Rules, opts.modulePath)
+	}
+	if opts.Resolve && rule == nil {
+		return fmt.Errorf("no rule matches %s", opts.modulePath)
+	}
+
+	dir := existing.New.Path
+	pinPath, pinVersion, pinned := config.LinkedPin(existing.Syntax)
+	if pinned {
+		if err := f.AddReplace(opts.modulePath, "", pinPath, pinVersion); err != nil {
+			return err
+		}
+		config.SetLinkedPin(existing.Syntax, "", "")
+	} else if err := f.DropReplace(opts.modulePath, ""); err != nil {
+		return err
+	}
+	if err := opts.writeModFile(f); err != nil {
+		return err
+	}
+	restored := "the required version"
+	if pinned {
+		restored = pinPath + "@" + pinVersion
+	}
+	if _, err := fmt.Fprintf(os.Stdout, "%s: %s => %s (was %s)\n", opts.GoModPath, opts.modulePath, restored, dir); err != nil {
+		return err
+	}
+	if rule == nil || (pinned && !opts.Resolve) {
+		return nil
+	}
+	replaceOpts := &replace.Options{
+		Options: goodmod.Options{
+			ConfigPath: opts.ConfigPath,
+			GoModPath:  opts.GoModPath,
+			Retries:    resolve.DefaultRetries,
+		},
+		ApplyReplace: true,
+		UpdateGoSum:  opts.UpdateGoSum,
+	}
+	return replaceOpts.Execute(ctx, []string{opts.modulePath})
+}
+
+func NewLinkCommand() *cobra.Command {
+	o := &Options{}
+
+	cmd := &cobra.Command{
+		Use:     "link <module> [dir]",
+		Example: linkExample,
+		Short:   "Replace module with local directory",
+		Long:    "Replace module with local directory checkout (default '../<repository>') and remember the version it was replaced with, so it can be restored by unlink",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 || len(args) > 2 {
+				return fmt.Errorf("module and optionally directory must be specified")
+			}
+			o.modulePath, o.dir = args[0], defaultDir(args[0])
+			if len(args) == 2 {
+				o.dir = args[1]
+			}
+			return o.Link()
+		},
+	}
+
+	o.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+func NewUnlinkCommand(ctx context.Context) *cobra.Command {
+	o := &Options{}
+
+	cmd := &cobra.Command{
+		Use:     "unlink <module>",
+		Example: unlinkExample,
+		Short:   "Restore module linked to local directory",
+		Long:    "Replace module linked to local directory with the version it was replaced with before, or resolve it using its rule",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("exactly one module must be specified")
+			}
+			o.modulePath = args[0]
+			return o.Unlink(ctx)
+		},
+	}
+
+	o.AddFlags(cmd.Flags())
+	cmd.Flags().BoolVar(&o.Resolve, "resolve", false, "Resolve the module using its rule instead of restoring the version it had before it was linked")
+	cmd.Flags().BoolVar(&o.UpdateGoSum, "update-go-sum", true, "When the module is resolved using its rule, add hashes of the new version to go.sum and remove hashes of versions no longer used")
+
+	return cmd
+}
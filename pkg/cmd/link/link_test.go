@@ -0,0 +1,134 @@
+package link
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testGoMod = `module example.com/test
+
+go 1.13
+
+require (
+	github.com/openshift/api v0.0.0-20191016115129-c07a134afb42
+	github.com/openshift/library-go v0.0.0-20191016115129-c07a134afb42
+)
+
+replace github.com/openshift/api => github.com/openshift/api v0.0.0-20191105131421-3d4e2ac1b2ad // goodmod: branch=master
+`
+
+func writeFile(t *testing.T, path, content string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func testWorkspace(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "goodmod-link")
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, "test", "go.mod"), testGoMod)
+	writeFile(t, filepath.Join(dir, "api", "go.mod"), "module github.com/openshift/api\n")
+	writeFile(t, filepath.Join(dir, "library-go", "go.mod"), "module github.com/openshift/library-go\n")
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+func TestDefaultDir(t *testing.T) {
+	for modulePath, expected := range map[string]string{
+		"github.com/openshift/library-go":    "../library-go",
+		"github.com/openshift/library-go/v2": "../library-go",
+		"k8s.io/api":                         "../api",
+	} {
+		if dir := defaultDir(modulePath); dir != expected {
+			t.Errorf("%s: expected %q, got %q", modulePath, expected, dir)
+		}
+	}
+}
+
+func TestLinkUnlinkRestorePin(t *testing.T) {
+	dir, cleanup := testWorkspace(t)
+	defer cleanup()
+	goModPath := filepath.Join(dir, "test", "go.mod")
+
+	o := &Options{GoModPath: goModPath, ConfigPath: filepath.Join(dir, "goodmod.yaml"), modulePath: "github.com/openshift/api", dir: defaultDir("github.com/openshift/api")}
+	if err := o.Link(); err != nil {
+		t.Fatal(err)
+	}
+	linked := readFile(t, goModPath)
+	expected := "github.com/openshift/api => ../api // goodmod-link: github.com/openshift/api@v0.0.0-20191105131421-3d4e2ac1b2ad; goodmod: branch=master"
+	if !strings.Contains(linked, expected) {
+		t.Fatalf("expected %q in go.mod, got:\n%s", expected, linked)
+	}
+
+	if err := o.Unlink(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	if restored := readFile(t, goModPath); restored != testGoMod {
+		t.Errorf("expected go.mod restored, got:\n%s", restored)
+	}
+}
+
+func TestLinkUnlinkRequired(t *testing.T) {
+	dir, cleanup := testWorkspace(t)
+	defer cleanup()
+	goModPath := filepath.Join(dir, "test", "go.mod")
+
+	o := &Options{GoModPath: goModPath, ConfigPath: filepath.Join(dir, "goodmod.yaml"), modulePath: "github.com/openshift/library-go", dir: filepath.Join(dir, "library-go")}
+	if err := o.Link(); err != nil {
+		t.Fatal(err)
+	}
+	if linked := readFile(t, goModPath); !strings.Contains(linked, "github.com/openshift/library-go => "+filepath.ToSlash(filepath.Join(dir, "library-go"))+"\n") {
+		t.Fatalf("expected library-go linked without pin, got:\n%s", linked)
+	}
+
+	o.Resolve = true
+	if err := o.Unlink(context.TODO()); err == nil || !strings.Contains(err.Error(), "no rule matches") {
+		t.Fatalf("expected error for --resolve without rule, got %v", err)
+	}
+	o.Resolve = false
+	if err := o.Unlink(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	if restored := readFile(t, goModPath); restored != testGoMod {
+		t.Errorf("expected replace dropped, got:\n%s", restored)
+	}
+}
+
+func TestLinkFailures(t *testing.T) {
+	dir, cleanup := testWorkspace(t)
+	defer cleanup()
+	goModPath := filepath.Join(dir, "test", "go.mod")
+
+	for _, tc := range []struct {
+		modulePath, dir, err string
+	}{
+		{modulePath: "github.com/openshift/client-go", dir: "../api", err: "neither required nor replaced"},
+		{modulePath: "github.com/openshift/api", dir: "../library-go", err: "is not a checkout of"},
+		{modulePath: "github.com/openshift/api", dir: "../missing", err: "is not a module directory"},
+	} {
+		o := &Options{GoModPath: goModPath, modulePath: tc.modulePath, dir: tc.dir}
+		if err := o.Link(); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s %s: expected error %q, got %v", tc.modulePath, tc.dir, tc.err, err)
+		}
+	}
+	o := &Options{GoModPath: goModPath, modulePath: "github.com/openshift/api"}
+	if err := o.Unlink(context.TODO()); err == nil || !strings.Contains(err.Error(), "not linked") {
+		t.Errorf("expected unlink of not linked module to fail, got %v", err)
+	}
+}
@@ -0,0 +1,163 @@
+package prune
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/mfojtik/goodmod/pkg/golang"
+)
+
+var example = `
+# Show replace directives that can be removed from go.mod
+goodmod prune
+
+# Remove stale and redundant replace directives from go.mod
+goodmod prune --apply
+`
+
+type Options struct {
+	GoModPath string
+	Apply     bool
+}
+
+// removal is a replace directive that should be removed from go.mod with the reason why.
+type removal struct {
+	replace *golang.Replace
+	reason  string
+}
+
+func (opts *Options) AddFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&opts.GoModPath, "gomod-file-path", "go.mod", "Specify the path to go.mod file")
+	flags.BoolVar(&opts.Apply, "apply", false, "Remove the replace directives from go.mod file")
+}
+
+// readSumModules return set of module paths listed in go.sum file. Modules listed in go.sum are part of the module graph.
+// When go.sum file does not exists, nil is returned.
+func readSumModules(goSumPath string) (map[string]bool, error) {
+	content, err := ioutil.ReadFile(goSumPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]bool{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		if fields := strings.Fields(scanner.Text()); len(fields) > 0 {
+			result[fields[0]] = true
+		}
+	}
+	return result, scanner.Err()
+}
+
+func formatReplace(r *golang.Replace) string {
+	old := r.Old.Path
+	if len(r.Old.Version) > 0 {
+		old += " " + r.Old.Version
+	}
+	return strings.TrimSpace(fmt.Sprintf("%s => %s %s", old, r.New.Path, r.New.Version))
+}
+
+// findRemovals return the replace directives that are duplicated, point to non-existing local directory, replace module
+// nothing require or replace module with the same version it is required.
+func findRemovals(f *golang.ModFile, goModDir string, sumModules map[string]bool) []removal {
+	required := map[string]string{}
+	for _, r := range f.Require {
+		required[r.Mod.Path] = r.Mod.Version
+	}
+
+	result := []removal{}
+	for i, r := range f.Replace {
+		duplicate := false
+		for _, other := range f.Replace[i+1:] {
+			// later replacements take priority
+			if other.Old == r.Old {
+				result = append(result, removal{replace: r, reason: fmt.Sprintf("duplicate of replace at line %d", other.Syntax.Start.Line)})
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			continue
+		}
+
+		requiredVersion, isRequired := required[r.Old.Path]
+		switch {
+		case !isRequired && (sumModules == nil || !sumModules[r.Old.Path]):
+			result = append(result, removal{replace: r, reason: "module is not required"})
+		case golang.IsDirectoryPath(r.New.Path):
+			dir := r.New.Path
+			if !filepath.IsAbs(dir) {
+				dir = filepath.Join(goModDir, dir)
+			}
+			if _, err := os.Stat(dir); os.IsNotExist(err) {
+				result = append(result, removal{replace: r, reason: fmt.Sprintf("local directory %q does not exist", dir)})
+			}
+		case r.New.Path == r.Old.Path && r.New.Version == requiredVersion && (len(r.Old.Version) == 0 || r.Old.Version == requiredVersion):
+			result = append(result, removal{replace: r, reason: "replace target is the required version"})
+		}
+	}
+	return result
+}
+
+func (opts *Options) Run() error {
+	modBytes, err := ioutil.ReadFile(opts.GoModPath)
+	if err != nil {
+		return err
+	}
+	f, err := golang.ParseModFile(opts.GoModPath, modBytes, nil)
+	if err != nil {
+		return err
+	}
+	goModDir := filepath.Dir(opts.GoModPath)
+	sumModules, err := readSumModules(filepath.Join(goModDir, "go.sum"))
+	if err != nil {
+		return err
+	}
+
+	removals := findRemovals(f, goModDir, sumModules)
+	for _, r := range removals {
+		if _, err := fmt.Fprintf(os.Stdout, "%s:%d: drop replace %s: %s\n", opts.GoModPath, r.replace.Syntax.Start.Line, formatReplace(r.replace), r.reason); err != nil {
+			return err
+		}
+	}
+	if !opts.Apply || len(removals) == 0 {
+		return nil
+	}
+	for _, r := range removals {
+		f.DropReplaceStmt(r.replace)
+	}
+	f.Cleanup()
+	out, err := f.Format()
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(opts.GoModPath, out, 0644)
+}
+
+func NewPruneCommand() *cobra.Command {
+	o := &Options{}
+
+	cmd := &cobra.Command{
+		Use:     "prune",
+		Example: example,
+		Short:   "Remove stale and redundant replace directives",
+		Long:    "Remove duplicated replace directives, replaces of modules nothing requires, replaces of the required version and replaces pointing to non-existing local directories",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run()
+		},
+	}
+
+	o.AddFlags(cmd.Flags())
+
+	return cmd
+}
@@ -0,0 +1,50 @@
+package prune
+
+import (
+	"testing"
+
+	"github.com/mfojtik/goodmod/pkg/golang"
+)
+
+const testGoMod = `module example.com/test
+
+go 1.13
+
+require (
+	github.com/openshift/api v0.0.0-20191016115129-c07a134afb42
+	k8s.io/api v0.17.0
+	k8s.io/apimachinery v0.17.0
+)
+
+replace (
+	// library-go is not required anymore
+	github.com/openshift/library-go => github.com/openshift/library-go v0.0.0-20191016115129-c07a134afb42
+	k8s.io/api => k8s.io/api v0.17.0
+	k8s.io/apimachinery => k8s.io/apimachinery v0.16.0
+	k8s.io/apimachinery => k8s.io/apimachinery v0.17.1
+	github.com/openshift/api => ../does-not-exists/api
+)
+`
+
+func TestFindRemovals(t *testing.T) {
+	f, err := golang.ParseModFile("go.mod", []byte(testGoMod), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	removals := findRemovals(f, ".", nil)
+	expected := map[int]string{
+		13: "module is not required",
+		14: "replace target is the required version",
+		15: "duplicate of replace at line 16",
+		17: `local directory "../does-not-exists/api" does not exist`,
+	}
+	if len(removals) != len(expected) {
+		t.Fatalf("expected %d removals, got %d: %#v", len(expected), len(removals), removals)
+	}
+	for _, r := range removals {
+		line := r.replace.Syntax.Start.Line
+		if expected[line] != r.reason {
+			t.Errorf("line %d: expected reason %q, got %q", line, expected[line], r.reason)
+		}
+	}
+}
@@ -3,51 +3,42 @@ package replace
 import (
 	"context"
 	"fmt"
-	"io/ioutil"
-	"net/http"
 	"os"
-	"os/exec"
 	"strings"
-	"sync"
+	"time"
 
-	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
-	"golang.org/x/oauth2"
 
-	"github.com/mfojtik/goodmod/pkg/config"
-	"github.com/mfojtik/goodmod/pkg/golang"
+	"github.com/mfojtik/goodmod/pkg/goodmod"
 	"github.com/mfojtik/goodmod/pkg/resolve"
-	"github.com/mfojtik/goodmod/pkg/resolve/branch"
-	"github.com/mfojtik/goodmod/pkg/resolve/commit"
-	"github.com/mfojtik/goodmod/pkg/resolve/tag"
-	"github.com/mfojtik/goodmod/pkg/resolve/types"
+	"github.com/mfojtik/goodmod/pkg/resolve/chain"
 )
 
-type moduleReplace struct {
-	oldPath        string
-	oldPathVersion string
-	newPath        string
-	newPathVersion string
-}
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
 
+// Options are the replace command options, the modules are resolved by goodmod.Planner and written by goodmod.Applier.
 type Options struct {
-	Branch string
-	Commit string
-	Tag    string
+	goodmod.Options
 
-	Paths      []string
-	Excludes   []string
-	GoModPath  string
-	ConfigPath string
 	SingleRule string
 
 	ApplyReplace bool
+	UpdateGoSum  bool
+	// Vendor refresh vendored packages of changed modules when applying, instead of running 'go mod vendor'
+	Vendor bool
 
-	GithubClient *http.Client
-	Verbose      bool
+	// Output is either 'text' (go mod edit commands) or 'json'
+	Output string
 
-	replaces []moduleReplace
+	// Timeout limit the duration of resolving all modules
+	Timeout time.Duration
+
+	// KeepGoing replace modules that resolved when other modules failed
+	KeepGoing bool
 }
 
 func (opts *Options) AddFlags(flags *pflag.FlagSet) {
@@ -55,267 +46,93 @@ func (opts *Options) AddFlags(flags *pflag.FlagSet) {
 	flags.StringVar(&opts.Branch, "branch", "", "Specify branch to use for this bump")
 	flags.StringVar(&opts.Tag, "tag", "", "Specify tag to use for this bump")
 	flags.StringVar(&opts.Commit, "commit", "", "Specify commit to use for this bump")
+	flags.StringVar(&opts.KubernetesVersion, "kubernetes-version", "", "Specify kubernetes version (eg. 'v1.19.2') which go.mod file dictate versions of all matching modules")
+	flags.StringVar(&opts.Follow, "follow", "", "Specify module which go.mod file (at version its rule or go.mod use) dictate versions of all matching modules")
+	flags.StringVar(&opts.AlignWith, "align-with", "", "Specify anchor module which go.mod file at the resolved commit dictate versions of all matching modules")
 	flags.StringVar(&opts.GoModPath, "gomod-file-path", "go.mod", "Specify the path to go.mod file")
+	flags.StringVar(&opts.Target, "target", goodmod.TargetGoMod, "Specify the file to write replace directives to ('go.mod' or 'go.work' to update the workspace replace block)")
+	flags.StringVar(&opts.GoWorkPath, "gowork-file-path", "go.work", "Specify the path to go.work file")
 	flags.BoolVar(&opts.ApplyReplace, "apply", false, "Apply the replace rules (execute 'go mod edit -replace' directly)")
-	flags.BoolVar(&opts.Verbose, "verbose", false, "Print more information about progress")
+	flags.BoolVar(&opts.UpdateGoSum, "update-go-sum", true, "When applying, add hashes of the new versions to go.sum and remove hashes of versions no longer used")
+	flags.BoolVar(&opts.Vendor, "vendor", false, "When applying, refresh vendored packages of changed modules and vendor/modules.txt without running 'go mod vendor'")
+	flags.StringVar(&opts.Output, "output", outputText, "Specify output format ('text' prints go mod edit commands, 'json' prints result for every module)")
+	flags.DurationVar(&opts.Timeout, "timeout", 0, "Specify maximum duration of resolving and fetching all modules (eg. '5m', zero means no timeout)")
+	flags.IntVar(&opts.Concurrency, "concurrency", goodmod.DefaultConcurrency, "Specify the number of modules resolved at once")
+	flags.BoolVar(&opts.KeepGoing, "keep-going", false, "Replace modules that resolved even when other modules failed to resolve (the command still fails)")
+	flags.BoolVar(&opts.FailFast, "fail-fast", false, "Stop resolving modules at the first module that failed to resolve")
+	flags.StringSliceVar(&opts.Resolvers, "resolvers", []string{}, fmt.Sprintf("Specify resolvers called in order to resolve branch, tag or commit separated by comma (any of: %s, default: %s)", strings.Join(chain.Names, ", "), strings.Join(chain.DefaultResolvers, ",")))
+	flags.StringVar(&opts.ExecResolver, "exec-resolver", "", "Specify the command called by the 'exec' resolver with module path, ref kind and ref name, it must print the commit as JSON")
+	flags.StringSliceVar(&opts.LocalRepos, "local-repos", []string{}, "Specify directories the 'local' resolver search existing clones in separated by comma (eg. '~/go/src', default: $GOPATH/src)")
+	flags.BoolVar(&opts.Offline, "offline", false, "Never use the network, resolve modules only from cache and local clones (unless --resolvers is set)")
+	flags.IntVar(&opts.Retries, "retries", resolve.DefaultRetries, "Specify the number of times GitHub requests failed because of rate limits or network errors are retried")
 	flags.StringSliceVar(&opts.Paths, "paths", []string{}, "Specify dependency path prefixes to update separated by comma (eg. 'github.com/openshift/api' or 'k8s.io/*')")
 	flags.StringSliceVar(&opts.Excludes, "excludes", []string{}, "Specify dependency path prefixes to exclude (eg. 'github.com/openshift/api' or 'k8s.io/')")
 }
 
-func (opts *Options) GetVersionsForPath(path string) (string, string) {
-	for _, p := range opts.replaces {
-		if p.oldPath == path {
-			return p.oldPathVersion, p.newPathVersion
-		}
-	}
-	return "", ""
-}
-
-func (opts *Options) hasReplacePath(path string) bool {
-	for _, p := range opts.replaces {
-		if p.oldPath == path {
-			return true
-		}
-	}
-	return false
-}
-
-// parseModules will parse the existing go.mod file and filter out only modules matching the name prefixes specified with this command
-func (opts *Options) parseModules() error {
-	modBytes, err := ioutil.ReadFile(opts.GoModPath)
-	if err != nil {
-		return err
-	}
-	s, err := golang.ParseModFile("go.mod", modBytes, nil)
-	if err != nil {
-		return err
-	}
-	opts.replaces = []moduleReplace{}
-	for _, r := range s.Replace {
-		if config.MatchPath(opts.Paths, opts.Excludes, r.Old.Path) {
-			opts.replaces = append(opts.replaces, moduleReplace{newPath: r.New.Path, oldPath: r.Old.Path, oldPathVersion: r.New.Version})
-		}
-	}
-	for _, r := range s.Require {
-		if !opts.hasReplacePath(r.Mod.Path) && config.MatchPath(opts.Paths, opts.Excludes, r.Mod.Path) {
-			opts.replaces = append(opts.replaces, moduleReplace{newPath: r.Mod.Path, oldPath: r.Mod.Path, oldPathVersion: r.Mod.Version})
-		}
-	}
-	return nil
-}
-
-// reportErrorForPath will report errors to standard error output, but prefix all messages with comment, so it can still
-// be passed via pipe to command.
-func reportErrorForPath(path string, reportedError error) {
-	d := color.New(color.FgHiRed, color.Bold)
-	if _, err := fmt.Fprintf(os.Stderr, "### "+d.Sprintf("ERROR: %s: %v\n", path, reportedError)); err != nil {
-		panic(err)
-	}
-}
-
-func reportVerbose(message string, objects ...interface{}) {
-	if _, err := fmt.Fprintf(os.Stderr, "# "+message+"\n", objects...); err != nil {
-		panic(err)
+func (opts *Options) Validate() error {
+	if opts.KeepGoing && opts.FailFast {
+		return fmt.Errorf("keep going cannot be combined with fail fast")
 	}
-}
-
-func (opts *Options) resolveByTag(modulePath string) *types.Commit {
-	resolvers := []resolve.ModulerResolver{
-		tag.NewGithubTagResolver(opts.GithubClient),
-		tag.NewGitTagResolver(),
+	if len(opts.Output) == 0 {
+		opts.Output = outputText
 	}
-	if opts.Verbose {
-		reportVerbose("Resolving module path %q using tag %q ...", modulePath, opts.Tag)
+	if opts.Output != outputText && opts.Output != outputJSON {
+		return fmt.Errorf("output must be %q or %q", outputText, outputJSON)
 	}
-	for _, r := range resolvers {
-		c, err := r.Resolve(context.TODO(), modulePath, opts.Tag)
-		if err != nil {
-			reportErrorForPath(modulePath, fmt.Errorf("failed to resolve tag using %T: %v", r, err))
-			continue
-		}
-		if opts.Verbose {
-			reportVerbose("Module path %q resolved to %q ...", modulePath, c.String())
-		}
-		return c
+	if opts.Target == goodmod.TargetGoWork && opts.Vendor {
+		return fmt.Errorf("vendor cannot be combined with %q target", goodmod.TargetGoWork)
 	}
 	return nil
 }
 
-func (opts *Options) resolveByBranch(modulePath string) *types.Commit {
-	resolvers := []resolve.ModulerResolver{
-		branch.NewGithubBranchResolver(opts.GithubClient),
-		branch.NewGitBranchResolver(),
-	}
-	if opts.Verbose {
-		reportVerbose("Resolving module path %q using branch %q ...", modulePath, opts.Branch)
-	}
-	for _, r := range resolvers {
-		c, err := r.Resolve(context.TODO(), modulePath, opts.Branch)
-		if err != nil {
-			reportErrorForPath(modulePath, fmt.Errorf("failed to resolve branch using %T: %v", r, err))
-			continue
-		}
-		if opts.Verbose {
-			reportVerbose("Module path %q resolved to %q ...", modulePath, c.String())
-		}
-		return c
+// Execute runs the replace for the rules in config file (or for the flags when there is no config file) and return
+// error instead of terminating the process, so it can be used by other commands.
+func (opts *Options) Execute(ctx context.Context, args []string) error {
+	if len(args) == 1 {
+		opts.SingleRule = strings.TrimSpace(args[0])
 	}
-	return nil
+	return opts.run(ctx, &goodmod.Planner{Options: opts.Options}, opts.SingleRule)
 }
 
-func (opts *Options) resolveByCommit(modulePath string) *types.Commit {
-	resolvers := []resolve.ModulerResolver{
-		commit.NewGithubCommitResolver(opts.GithubClient),
-		commit.NewGitCommitResolver(),
-	}
-	if opts.Verbose {
-		reportVerbose("Resolving module path %q using commit %q ...", modulePath, opts.Commit)
-	}
-	for _, r := range resolvers {
-		c, err := r.Resolve(context.TODO(), modulePath, opts.Commit)
-		if err != nil {
-			reportErrorForPath(modulePath, fmt.Errorf("failed to resolve commit: %v", err))
-			continue
-		}
-		if opts.Verbose {
-			reportVerbose("Module path %q resolved to %q ...", modulePath, c.String())
-		}
-		return c
-	}
-	return nil
+// RunOnce resolve and replace the modules set in options, ignoring the rules of the config file (its settings like
+// GitHub hosts, git auth and resolvers are used). The error is returned when any module failed to resolve.
+func (opts *Options) RunOnce(ctx context.Context) error {
+	return opts.run(ctx, &goodmod.Planner{Options: opts.Options, IgnoreRules: true}, "")
 }
 
-func (opts *Options) Complete() error {
-	if err := opts.parseModules(); err != nil {
+func (opts *Options) run(ctx context.Context, planner *goodmod.Planner, modulePath string) error {
+	if err := opts.Validate(); err != nil {
 		return err
 	}
-
-	if len(opts.replaces) == 0 {
-		return fmt.Errorf("no modules found with given path prefixes: %#v", opts.Paths)
-	}
-
-	errChan := make(chan error)
-	defer close(errChan)
-
-	var wg sync.WaitGroup
-	wg.Add(len(opts.replaces))
-
-	for i := range opts.replaces {
-		go func(index int) {
-			defer wg.Done()
-			replace := opts.replaces[index]
-			var (
-				foundCommit *types.Commit
-			)
-
-			if len(opts.Branch) > 0 {
-				foundCommit = opts.resolveByBranch(replace.newPath)
-			}
-
-			if len(opts.Tag) > 0 {
-				foundCommit = opts.resolveByTag(replace.newPath)
-			}
-
-			if len(opts.Commit) > 0 {
-				foundCommit = opts.resolveByCommit(replace.newPath)
-			}
-
-			if foundCommit == nil {
-				reportErrorForPath(replace.newPath, fmt.Errorf("unable to get commit"))
-				return
-			}
-			opts.replaces[index].newPathVersion = foundCommit.String()
-		}(i)
-	}
-
-	wg.Wait()
-	return nil
-}
-
-func (opts *Options) applyReplace(replace moduleReplace) error {
-	cmd := exec.Command("go", "mod", "edit", "-replace", fmt.Sprintf(`%s=%s@%s`, replace.oldPath, replace.newPath, replace.newPathVersion))
-	outBytes, err := cmd.CombinedOutput()
+	ctx, cancel := resolve.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+	plan, err := planner.Plan(ctx, modulePath)
 	if err != nil {
-		return fmt.Errorf("%s: %v\n%s", strings.Join(cmd.Args, " "), err, string(outBytes))
+		return err
 	}
-	return nil
-}
-
-func (opts *Options) Run() error {
-	for _, replace := range opts.replaces {
-		if len(replace.newPathVersion) == 0 {
-			continue
-		}
-		if _, err := fmt.Fprintf(os.Stdout, `go mod edit -replace %s=%s@"%s"`+"\n", replace.oldPath, replace.newPath, replace.newPathVersion); err != nil {
-			return err
+	// the changes are only applied when all modules resolved, unless asked to keep going
+	applied := len(plan.Failed()) == 0 || opts.KeepGoing
+	if applied {
+		if opts.Output == outputText {
+			for _, command := range plan.Commands() {
+				if _, err := fmt.Fprintln(os.Stdout, command); err != nil {
+					return err
+				}
+			}
 		}
 		if opts.ApplyReplace {
-			if err := opts.applyReplace(replace); err != nil {
+			applier := &goodmod.Applier{UpdateGoSum: opts.UpdateGoSum, Vendor: opts.Vendor}
+			if err := applier.Apply(ctx, plan); err != nil {
 				return err
 			}
 		}
 	}
-	return nil
-}
-
-func (opts *Options) Validate() error {
-	if len(opts.Branch) == 0 && len(opts.Commit) == 0 && len(opts.Tag) == 0 {
-		return fmt.Errorf("either branch, commit or tag must be specified")
-	}
-	if len(opts.Paths) == 0 {
-		return fmt.Errorf("dependency name must be specified")
-	}
-	return nil
-}
-
-func reportFatal(message interface{}, objects ...interface{}) {
-	formatMessage := ""
-	switch v := message.(type) {
-	case error:
-		formatMessage = v.Error()
-	case string:
-		formatMessage = v
-	}
-	if _, err := fmt.Fprintf(os.Stderr, "ERROR: "+formatMessage+"\n", objects...); err != nil {
-		panic(err)
-	}
-	os.Exit(1)
-}
-
-func (opts *Options) RunCommand(cmd *cobra.Command, args []string) {
-	if ghToken := os.Getenv("GITHUB_TOKEN"); len(ghToken) > 0 {
-		opts.GithubClient = oauth2.NewClient(context.TODO(), oauth2.StaticTokenSource(&oauth2.Token{AccessToken: ghToken}))
-	}
-	if len(args) == 1 {
-		opts.SingleRule = strings.TrimSpace(args[0])
-	}
-	options, noConfig, err := ConfigToOptions(opts.ConfigPath, opts.SingleRule, *opts)
-	if err != nil {
-		reportFatal(err)
-	}
-	// we don't have config passed, RunCommand using flags
-	if noConfig {
-		opts.RunOnce(cmd, args)
-		return
-	}
-	for _, o := range options {
-		o.RunOnce(cmd, args)
-
-		// TODO: This will only preserve last replace set
-		opts.replaces = o.replaces
-	}
-}
-
-func (opts *Options) RunOnce(cmd *cobra.Command, args []string) {
-	if err := opts.Validate(); err != nil {
-		reportFatal(err)
-	}
-	if err := opts.Complete(); err != nil {
-		reportFatal(err)
-	}
-	if err := opts.Run(); err != nil {
-		reportFatal(err)
+	if opts.Output == outputJSON {
+		if err := printJSON(plan.Changes); err != nil {
+			return err
+		}
 	}
+	return Summarize(plan.Changes, applied)
 }
 
 var example = `
@@ -329,11 +146,14 @@ goodmod replace --paths=github.com/openshift/* --branch=master
 # The goodmod.yaml config file MUST specify at least one rule for this path.
 goodmod replace github.com/openshift/library-go
 
+# Update all k8s.io/* paths to versions used by kubernetes v1.19.2
+goodmod replace --kubernetes-version=v1.19.2
+
 # Update all modules specified in goodmod.yaml and apply changes to go.mod directly
 goodmod replace --apply --verbose
 `
 
-func NewReplaceCommand() *cobra.Command {
+func NewReplaceCommand(ctx context.Context) *cobra.Command {
 	replaceOptions := &Options{}
 
 	cmd := &cobra.Command{
@@ -341,7 +161,9 @@ func NewReplaceCommand() *cobra.Command {
 		Example: example,
 		Short:   "Replace multiple modules at once",
 		Long:    "Replace help to perform bulk operations on go.mod replace in case you want to track branch, tag or commit for single path",
-		Run:     replaceOptions.RunCommand,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return replaceOptions.Execute(ctx, args)
+		},
 	}
 	replaceOptions.AddFlags(cmd.Flags())
 
@@ -0,0 +1,54 @@
+package replace
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/mfojtik/goodmod/pkg/goodmod"
+	"github.com/mfojtik/goodmod/pkg/log"
+)
+
+// Summarize print failed and skipped modules with reasons and the number of resolved, unchanged, skipped and failed
+// modules. The error is returned when modules failed to resolve, applied tell whether the resolved modules were replaced.
+func Summarize(changes []goodmod.Change, applied bool) error {
+	resolved, unchanged, skipped, failed := 0, 0, 0, 0
+	for _, c := range changes {
+		switch {
+		case len(c.Failure) > 0:
+			failed++
+			log.WithModule(c.OldPath).Errorf("failed: %s", c.Failure)
+		case len(c.Skipped) > 0:
+			skipped++
+			log.WithModule(c.OldPath).Warningf("skipped: %s", c.Skipped)
+		case c.Changed:
+			resolved++
+		default:
+			unchanged++
+		}
+	}
+	counts := fmt.Sprintf("%d modules resolved, %d unchanged", resolved, unchanged)
+	if skipped > 0 {
+		counts += fmt.Sprintf(", %d skipped", skipped)
+	}
+	switch {
+	case failed == 0:
+		log.Infof("%s", counts)
+		return nil
+	case applied:
+		log.Warningf("%s, %d failed", counts, failed)
+		return fmt.Errorf("%d modules failed to resolve, only resolved modules were replaced", failed)
+	default:
+		log.Warningf("%s, %d failed", counts, failed)
+		return fmt.Errorf("%d modules failed to resolve, no modules were replaced (use --keep-going to replace resolved modules)", failed)
+	}
+}
+
+func printJSON(changes []goodmod.Change) error {
+	out, err := json.MarshalIndent(changes, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(os.Stdout, string(out))
+	return err
+}
@@ -0,0 +1,71 @@
+package syncfrom
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/mfojtik/goodmod/pkg/cmd/replace"
+	"github.com/mfojtik/goodmod/pkg/resolve"
+)
+
+var example = `
+# Copy versions of all k8s.io/* modules from 'github.com/openshift/origin' go.mod in 'master' branch
+goodmod sync-from github.com/openshift/origin --branch=master --paths=k8s.io/*
+
+# Copy versions of all k8s.io/* modules from local go.mod file and apply them
+goodmod sync-from ../controller-runtime/go.mod --paths=k8s.io/* --apply
+`
+
+type Options struct {
+	replace.Options
+}
+
+func (opts *Options) AddFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&opts.ConfigPath, "config", "goodmod.yaml", "Specify file to read the settings (eg. GitHub hosts, git auth and resolvers) from, its rules are not used")
+	flags.StringVar(&opts.Branch, "branch", "", "Specify branch of the source module to copy versions from")
+	flags.StringVar(&opts.Tag, "tag", "", "Specify tag of the source module to copy versions from")
+	flags.StringVar(&opts.Commit, "commit", "", "Specify commit of the source module to copy versions from")
+	flags.StringVar(&opts.GoModPath, "gomod-file-path", "go.mod", "Specify the path to go.mod file")
+	flags.BoolVar(&opts.ApplyReplace, "apply", false, "Apply the changes (execute 'go mod edit' directly)")
+	flags.BoolVar(&opts.UpdateGoSum, "update-go-sum", true, "When applying, add hashes of the new versions to go.sum and remove hashes of versions no longer used")
+	flags.IntVar(&opts.Retries, "retries", resolve.DefaultRetries, "Specify the number of times GitHub requests failed because of rate limits or network errors are retried")
+	flags.DurationVar(&opts.Timeout, "timeout", 0, "Specify maximum duration of resolving and fetching all modules (eg. '5m', zero means no timeout)")
+	flags.StringSliceVar(&opts.Paths, "paths", []string{}, "Specify dependency path prefixes to copy separated by comma (eg. 'github.com/openshift/api' or 'k8s.io/*')")
+	flags.StringSliceVar(&opts.Excludes, "excludes", []string{}, "Specify dependency path prefixes to exclude (eg. 'github.com/openshift/api' or 'k8s.io/')")
+}
+
+func (opts *Options) Complete(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("exactly one module, repository or go.mod file path must be specified")
+	}
+	opts.SyncFrom = args[0]
+	opts.GithubClient = resolve.NewGithubClient(os.Getenv("GITHUB_TOKEN"), opts.Retries)
+	return nil
+}
+
+func NewSyncFromCommand(ctx context.Context) *cobra.Command {
+	o := &Options{}
+
+	cmd := &cobra.Command{
+		Use:     "sync-from <module|repository|go.mod>",
+		Example: example,
+		Short:   "Copy versions of modules from another project go.mod file",
+		Long:    "Copy versions and replace targets of all modules matching paths from another project go.mod file at given branch, tag or commit",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(args); err != nil {
+				return err
+			}
+			ctx, cancel := resolve.WithTimeout(ctx, o.Timeout)
+			defer cancel()
+			return o.RunOnce(ctx)
+		},
+	}
+
+	o.AddFlags(cmd.Flags())
+
+	return cmd
+}
@@ -0,0 +1,206 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/mfojtik/goodmod/pkg/golang"
+)
+
+// annotationPrefix starts the rule in go.mod line comment (eg. '// goodmod: tag=kubernetes-1.16.2').
+const annotationPrefix = "goodmod:"
+
+// annotationKeys are the rule fields that can be set by annotation, using the same names as in config file.
+var annotationKeys = []string{"branch", "tag", "commit", "alignWith", "kubernetesVersion", "syncFrom", "follow", "resolvers"}
+
+// resolversKey set the rule resolvers as a comma separated list (eg. 'resolvers=cache,git').
+const resolversKey = "resolvers"
+
+func ruleField(rule *Rule, key string) *string {
+	switch key {
+	case "branch":
+		return &rule.BranchName
+	case "tag":
+		return &rule.TagName
+	case "commit":
+		return &rule.Commit
+	case "alignWith":
+		return &rule.AlignWith
+	case "kubernetesVersion":
+		return &rule.KubernetesVersion
+	case "syncFrom":
+		return &rule.SyncFrom
+	case "follow":
+		return &rule.Follow
+	}
+	return nil
+}
+
+// ParseAnnotation parse the rule from go.mod line comment (eg. '// goodmod: tag=kubernetes-1.16.2'). The rule paths are
+// not set. When the comment does not include annotation, nil is returned.
+func ParseAnnotation(comment string) (*Rule, error) {
+	i := strings.Index(comment, annotationPrefix)
+	if i < 0 {
+		return nil, nil
+	}
+	rule := &Rule{}
+	fields := strings.Fields(comment[i+len(annotationPrefix):])
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty annotation, expected key=value (eg. 'goodmod: tag=v1.0.0')")
+	}
+	for _, f := range fields {
+		parts := strings.SplitN(f, "=", 2)
+		if len(parts) != 2 || len(parts[1]) == 0 {
+			return nil, fmt.Errorf("invalid annotation %q, expected key=value", f)
+		}
+		if parts[0] == resolversKey {
+			rule.Resolvers = strings.Split(parts[1], ",")
+			continue
+		}
+		field := ruleField(rule, parts[0])
+		if field == nil {
+			return nil, fmt.Errorf("unknown annotation key %q, must be one of: %s", parts[0], strings.Join(annotationKeys, ", "))
+		}
+		*field = parts[1]
+	}
+	return rule, nil
+}
+
+// FormatAnnotation return the annotation for the rule (eg. 'goodmod: tag=kubernetes-1.16.2').
+func FormatAnnotation(rule Rule) string {
+	fields := []string{annotationPrefix}
+	for _, key := range annotationKeys {
+		if key == resolversKey {
+			if len(rule.Resolvers) > 0 {
+				fields = append(fields, key+"="+strings.Join(rule.Resolvers, ","))
+			}
+			continue
+		}
+		if value := *ruleField(&rule, key); len(value) > 0 {
+			fields = append(fields, key+"="+value)
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+// lineAnnotation return the annotation rule from the line end-of-line comment.
+func lineAnnotation(line *golang.Line) (*Rule, error) {
+	if line == nil || len(line.Suffix) == 0 {
+		return nil, nil
+	}
+	return ParseAnnotation(line.Suffix[0].Token)
+}
+
+// AnnotationRules return the rules from annotations on replace and require lines in go.mod file. Replace annotations
+// take priority over require annotations of the same module.
+func AnnotationRules(f *golang.ModFile) ([]Rule, error) {
+	rules := []Rule{}
+	seen := map[string]bool{}
+	add := func(modulePath string, line *golang.Line) error {
+		if seen[modulePath] {
+			return nil
+		}
+		rule, err := lineAnnotation(line)
+		if err != nil {
+			return fmt.Errorf("%s:%d: %v", f.Syntax.Name, line.Start.Line, err)
+		}
+		if rule == nil {
+			return nil
+		}
+		rule.Paths = []string{modulePath}
+		rules = append(rules, *rule)
+		seen[modulePath] = true
+		return nil
+	}
+	for _, r := range f.Replace {
+		if err := add(r.Old.Path, r.Syntax); err != nil {
+			return nil, err
+		}
+	}
+	for _, r := range f.Require {
+		if err := add(r.Mod.Path, r.Syntax); err != nil {
+			return nil, err
+		}
+	}
+	return rules, nil
+}
+
+// ReadAnnotationRules read the go.mod file and return the rules from its annotations. When go.mod file does not exists,
+// no rules are returned.
+func ReadAnnotationRules(goModPath string) ([]Rule, error) {
+	modBytes, err := ioutil.ReadFile(goModPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	f, err := golang.ParseModFile(goModPath, modBytes, nil)
+	if err != nil {
+		return nil, err
+	}
+	return AnnotationRules(f)
+}
+
+// MergeRules return the config file rules followed by annotation rules for modules no config file rule match, so config
+// file rules override annotations.
+func MergeRules(rules, annotations []Rule) []Rule {
+	result := append([]Rule{}, rules...)
+	for _, a := range annotations {
+		if RuleForPath(rules, a.Paths[0]) != nil {
+			continue
+		}
+		result = append(result, a)
+	}
+	return result
+}
+
+// ReadRules read the config file and add rules from go.mod annotations. NotFoundError is returned only when there is
+// neither config file nor annotation.
+func ReadRules(configPath, goModPath string) (*Config, error) {
+	c, err := ReadConfig(configPath)
+	if err != nil && err != NotFoundError {
+		return nil, err
+	}
+	annotations, err := ReadAnnotationRules(goModPath)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		if len(annotations) == 0 {
+			return nil, NotFoundError
+		}
+		c = &Config{}
+	}
+	c.Rules = MergeRules(c.Rules, annotations)
+	return c, nil
+}
+
+// SetAnnotation set the annotation for the rule in line end-of-line comment, keeping other comment text
+// (eg. '// indirect'). When rule is nil, the annotation is removed.
+func SetAnnotation(line *golang.Line, rule *Rule) {
+	comment := ""
+	if len(line.Suffix) > 0 {
+		comment = strings.TrimSpace(strings.TrimPrefix(line.Suffix[0].Token, "//"))
+		if i := strings.Index(comment, annotationPrefix); i >= 0 {
+			comment = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(comment[:i]), ";"))
+		}
+	}
+	if rule != nil {
+		if len(comment) > 0 {
+			comment += "; "
+		}
+		comment += FormatAnnotation(*rule)
+	}
+	if len(comment) == 0 {
+		line.Suffix = nil
+		return
+	}
+	if len(line.Suffix) == 0 {
+		line.Suffix = []golang.Comment{{Token: "// " + comment, Suffix: true}}
+		return
+	}
+	line.Suffix[0].Token = "// " + comment
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mfojtik/goodmod/pkg/golang"
+)
+
+const testAnnotatedGoMod = `module example.com/mod
+
+require (
+	github.com/openshift/api v0.0.0-20191016115129-c07a134afb42 // indirect; goodmod: branch=master
+	k8s.io/api v0.0.0-20191004115801-a2eda9f80ab8
+	k8s.io/client-go v0.17.0
+)
+
+replace k8s.io/api => k8s.io/api v0.0.0-20191004115801-a2eda9f80ab8 // goodmod: tag=kubernetes-1.16.2
+`
+
+func TestParseAnnotation(t *testing.T) {
+	tests := []struct {
+		comment  string
+		expected *Rule
+		err      bool
+	}{
+		{comment: "// indirect"},
+		{comment: "// goodmod: tag=kubernetes-1.16.2", expected: &Rule{TagName: "kubernetes-1.16.2"}},
+		{comment: "// indirect; goodmod: branch=master follow=github.com/openshift/library-go", expected: &Rule{BranchName: "master", Follow: "github.com/openshift/library-go"}},
+		{comment: "// goodmod: tag=v1.0.0 resolvers=cache,git", expected: &Rule{TagName: "v1.0.0", Resolvers: []string{"cache", "git"}}},
+		{comment: "// goodmod:", err: true},
+		{comment: "// goodmod: tag", err: true},
+		{comment: "// goodmod: version=v1.0.0", err: true},
+	}
+	for _, test := range tests {
+		rule, err := ParseAnnotation(test.comment)
+		if (err != nil) != test.err {
+			t.Errorf("%q: unexpected error: %v", test.comment, err)
+			continue
+		}
+		if !reflect.DeepEqual(rule, test.expected) {
+			t.Errorf("%q: expected %#v, got %#v", test.comment, test.expected, rule)
+		}
+	}
+	if got := FormatAnnotation(Rule{Paths: []string{"k8s.io/*"}, TagName: "v0.19.2", Follow: "k8s.io/kubernetes"}); got != "goodmod: tag=v0.19.2 follow=k8s.io/kubernetes" {
+		t.Errorf("unexpected annotation %q", got)
+	}
+	if got := FormatAnnotation(Rule{TagName: "v1.0.0", Resolvers: []string{"proxy", "git"}}); got != "goodmod: tag=v1.0.0 resolvers=proxy,git" {
+		t.Errorf("unexpected annotation %q", got)
+	}
+}
+
+func TestAnnotationRules(t *testing.T) {
+	f, err := golang.ParseModFile("go.mod", []byte(testAnnotatedGoMod), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	annotations, err := AnnotationRules(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Rule{
+		{Paths: []string{"k8s.io/api"}, TagName: "kubernetes-1.16.2"},
+		{Paths: []string{"github.com/openshift/api"}, BranchName: "master"},
+	}
+	if !reflect.DeepEqual(annotations, expected) {
+		t.Fatalf("unexpected rules: %#v", annotations)
+	}
+	if !f.Require[0].Indirect {
+		t.Error("expected annotated require to stay indirect")
+	}
+
+	rules := MergeRules([]Rule{{Paths: []string{"k8s.io/*"}, TagName: "kubernetes-1.17.0"}}, annotations)
+	if len(rules) != 2 || rules[0].TagName != "kubernetes-1.17.0" || rules[1].BranchName != "master" {
+		t.Errorf("expected config file rule to override annotation: %#v", rules)
+	}
+
+	SetAnnotation(f.Require[0].Syntax, nil)
+	SetAnnotation(f.Require[2].Syntax, &Rule{TagName: "v0.17.1"})
+	SetAnnotation(f.Replace[0].Syntax, &Rule{TagName: "kubernetes-1.17.0"})
+	out, err := f.Format()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedGoMod := `module example.com/mod
+
+require (
+	github.com/openshift/api v0.0.0-20191016115129-c07a134afb42 // indirect
+	k8s.io/api v0.0.0-20191004115801-a2eda9f80ab8
+	k8s.io/client-go v0.17.0 // goodmod: tag=v0.17.1
+)
+
+replace k8s.io/api => k8s.io/api v0.0.0-20191004115801-a2eda9f80ab8 // goodmod: tag=kubernetes-1.17.0
+`
+	if string(out) != expectedGoMod {
+		t.Errorf("unexpected go.mod:\n%s", out)
+	}
+}
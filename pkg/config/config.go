@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"time"
 
 	"github.com/gobwas/glob"
 	"gopkg.in/yaml.v2"
@@ -16,14 +17,75 @@ type Config struct {
 	// Rules include rules for individual go mod paths
 	Rules         []Rule `yaml:"rules,omitempty"`
 	GoModFilePath string `yaml:"gomodPath,omitempty"`
+
+	// Verify include commands (eg. 'go build ./...') that must succeed before the bump is committed
+	Verify []string `yaml:"verify,omitempty"`
+
+	// ResolverTimeouts limit the duration of every call of the resolver kind ('github', 'git' or 'proxy'), eg. 'git: 2m'
+	ResolverTimeouts map[string]time.Duration `yaml:"resolverTimeouts,omitempty"`
+
+	// Resolvers are the names of resolvers called in order to resolve branch, tag or commit of all rules (eg. 'cache',
+	// 'proxy', 'github', 'git' or 'exec')
+	Resolvers []string `yaml:"resolvers,omitempty"`
+	// ExecResolver is the command called by the 'exec' resolver with the module path, ref kind and ref name
+	ExecResolver string `yaml:"execResolver,omitempty"`
+
+	// GitAuth select the authentication of git clones by module path, the first rule matching the module is used
+	GitAuth []GitAuthRule `yaml:"gitAuth,omitempty"`
+
+	// GithubHosts are the GitHub Enterprise Server hosts modules are hosted on, in addition to github.com
+	GithubHosts []GithubHost `yaml:"githubHosts,omitempty"`
+}
+
+// GithubHost is a GitHub Enterprise Server that GitHub resolvers use for modules with paths starting with its host.
+type GithubHost struct {
+	// Host is the host name in module paths (eg. 'github.corp.example.com')
+	Host string `yaml:"host"`
+	// APIURL (default 'https://<host>/api/v3/') and UploadURL (default 'https://<host>/api/uploads/') are the base URLs
+	// of the REST API
+	APIURL    string `yaml:"apiURL,omitempty"`
+	UploadURL string `yaml:"uploadURL,omitempty"`
+	// TokenEnv is the environment variable with the API token of the host (eg. 'GHE_TOKEN')
+	TokenEnv string `yaml:"tokenEnv,omitempty"`
+}
+
+// GitAuthRule select the authentication of git clones for modules matching the patterns.
+type GitAuthRule struct {
+	// Patterns are comma separated glob patterns of module path prefixes, the same as in GOPRIVATE
+	// (eg. 'git.corp.example.com,github.com/myorg/*')
+	Patterns string `yaml:"patterns"`
+	// Method is 'ssh', 'netrc', 'credential-helper', 'auto' (netrc or credential helper) or 'none'
+	Method string `yaml:"method"`
+	// SSHUser (default 'git') and SSHKey (private key file, SSH agent is used when empty) are used by the ssh method
+	SSHUser string `yaml:"sshUser,omitempty"`
+	SSHKey  string `yaml:"sshKey,omitempty"`
 }
 
 type Rule struct {
-	Paths      []string `yaml:"paths"`
-	Excludes   []string `yaml:"excludes,omitempty"`
-	BranchName string   `yaml:"branch,omitempty"`
-	TagName    string   `yaml:"tag,omitempty"`
-	Commit     string   `yaml:"commit,omitempty"`
+	Paths      []string `yaml:"paths" json:"paths"`
+	Excludes   []string `yaml:"excludes,omitempty" json:"excludes,omitempty"`
+	BranchName string   `yaml:"branch,omitempty" json:"branch,omitempty"`
+	TagName    string   `yaml:"tag,omitempty" json:"tag,omitempty"`
+	Commit     string   `yaml:"commit,omitempty" json:"commit,omitempty"`
+
+	// AlignWith is an anchor module resolved using the branch, tag or commit. The versions of all modules matching paths
+	// are then set to versions required by the anchor module go.mod file.
+	AlignWith string `yaml:"alignWith,omitempty" json:"alignWith,omitempty"`
+
+	// KubernetesVersion (eg. 'v1.19.2') set versions of all modules matching paths to versions kubernetes/kubernetes
+	// go.mod use at that version tag. Staging modules (eg. k8s.io/api) are set to the matching published version (v0.19.2).
+	KubernetesVersion string `yaml:"kubernetesVersion,omitempty" json:"kubernetesVersion,omitempty"`
+
+	// SyncFrom is a module (eg. 'github.com/openshift/origin') resolved using the branch, tag or commit or a local go.mod
+	// file path. The versions and replace targets of all modules matching paths are copied from its go.mod file.
+	SyncFrom string `yaml:"syncFrom,omitempty" json:"syncFrom,omitempty"`
+
+	// Follow is a module (eg. 'github.com/openshift/library-go') resolved using its own rule or the version go.mod use.
+	// The versions of all modules matching paths are set to versions required by the followed module go.mod file.
+	Follow string `yaml:"follow,omitempty" json:"follow,omitempty"`
+
+	// Resolvers override the resolvers called to resolve the branch, tag or commit for this rule.
+	Resolvers []string `yaml:"resolvers,omitempty" json:"resolvers,omitempty"`
 }
 
 func ReadConfig(configPath string) (*Config, error) {
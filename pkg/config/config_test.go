@@ -1,4 +1,4 @@
-package replace
+package config
 
 import (
 	"testing"
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/mfojtik/goodmod/pkg/golang"
+)
+
+// linkPrefix starts the pin the module had before it was linked to local directory, it is kept in the replace line
+// comment (eg. '// goodmod-link: github.com/openshift/library-go@v0.0.0-20191016115129-c07a134afb42').
+const linkPrefix = "goodmod-link:"
+
+// commentParts return the parts of the line end-of-line comment separated by ';'.
+func commentParts(line *golang.Line) []string {
+	if len(line.Suffix) == 0 {
+		return nil
+	}
+	parts := []string{}
+	for _, part := range strings.Split(strings.TrimPrefix(line.Suffix[0].Token, "//"), ";") {
+		if part = strings.TrimSpace(part); len(part) > 0 {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+// LinkedPin return the module path and version the replace pointed to before the module was linked to local
+// directory. False is returned when the line has no pin.
+func LinkedPin(line *golang.Line) (string, string, bool) {
+	for _, part := range commentParts(line) {
+		if !strings.HasPrefix(part, linkPrefix) {
+			continue
+		}
+		pin := strings.TrimSpace(strings.TrimPrefix(part, linkPrefix))
+		if i := strings.LastIndex(pin, "@"); i > 0 {
+			return pin[:i], pin[i+1:], true
+		}
+	}
+	return "", "", false
+}
+
+// SetLinkedPin set the pin in the line end-of-line comment, before the annotation and keeping other comment text. When
+// the path is empty, the pin is removed.
+func SetLinkedPin(line *golang.Line, path, version string) {
+	parts := []string{}
+	pinned := len(path) == 0
+	for _, part := range commentParts(line) {
+		if strings.HasPrefix(part, linkPrefix) {
+			continue
+		}
+		// the annotation is the rest of the comment
+		if strings.Contains(part, annotationPrefix) && !pinned {
+			parts = append(parts, linkPrefix+" "+path+"@"+version)
+			pinned = true
+		}
+		parts = append(parts, part)
+	}
+	if !pinned {
+		parts = append(parts, linkPrefix+" "+path+"@"+version)
+	}
+	if len(parts) == 0 {
+		line.Suffix = nil
+		return
+	}
+	comment := "// " + strings.Join(parts, "; ")
+	if len(line.Suffix) == 0 {
+		line.Suffix = []golang.Comment{{Token: comment, Suffix: true}}
+		return
+	}
+	line.Suffix[0].Token = comment
+}
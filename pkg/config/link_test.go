@@ -0,0 +1,44 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/mfojtik/goodmod/pkg/golang"
+)
+
+func TestSetLinkedPin(t *testing.T) {
+	tests := []struct {
+		comment  string
+		path     string
+		version  string
+		expected string
+	}{
+		{path: "github.com/openshift/api", version: "v0.1.0", expected: "// goodmod-link: github.com/openshift/api@v0.1.0"},
+		{comment: "// indirect; goodmod: branch=master", path: "github.com/openshift/api", version: "v0.1.0", expected: "// indirect; goodmod-link: github.com/openshift/api@v0.1.0; goodmod: branch=master"},
+		{comment: "// goodmod-link: github.com/openshift/api@v0.1.0; goodmod: branch=master", expected: "// goodmod: branch=master"},
+		{comment: "// goodmod-link: github.com/openshift/api@v0.1.0"},
+	}
+	for _, test := range tests {
+		line := &golang.Line{}
+		if len(test.comment) > 0 {
+			line.Suffix = []golang.Comment{{Token: test.comment, Suffix: true}}
+		}
+		SetLinkedPin(line, test.path, test.version)
+		comment := ""
+		if len(line.Suffix) > 0 {
+			comment = line.Suffix[0].Token
+		}
+		if comment != test.expected {
+			t.Errorf("%q: expected %q, got %q", test.comment, test.expected, comment)
+			continue
+		}
+		path, version, ok := LinkedPin(line)
+		if ok != (len(test.path) > 0) || path != test.path || version != test.version {
+			t.Errorf("%q: expected pin %s@%s, got %s@%s", test.comment, test.path, test.version, path, version)
+		}
+		// the pin must not break the annotation
+		if _, err := lineAnnotation(line); err != nil {
+			t.Errorf("%q: unexpected annotation error: %v", comment, err)
+		}
+	}
+}
@@ -16,23 +16,30 @@ import (
 
 	"github.com/mfojtik/goodmod/pkg/golang/internal/lazyregexp"
 	"github.com/mfojtik/goodmod/pkg/golang/internal/module"
+	"github.com/mfojtik/goodmod/pkg/golang/internal/semver"
 )
 
 // A File is the parsed, interpreted form of a go.mod file.
 type File struct {
-	Module  *Module
-	Go      *Go
-	Require []*Require
-	Exclude []*Exclude
-	Replace []*Replace
+	Module    *Module
+	Go        *Go
+	Toolchain *Toolchain
+	Godebug   []*Godebug
+	Require   []*Require
+	Exclude   []*Exclude
+	Replace   []*Replace
+	Retract   []*Retract
+	Tool      []*Tool
+	Ignore    []*Ignore
 
 	Syntax *FileSyntax
 }
 
 // A Module is the module statement.
 type Module struct {
-	Mod    module.Version
-	Syntax *Line
+	Mod        module.Version
+	Deprecated string // "Deprecated:" comment text, if any
+	Syntax     *Line
 }
 
 // A Go is the go statement.
@@ -41,6 +48,19 @@ type Go struct {
 	Syntax  *Line
 }
 
+// A Toolchain is the toolchain statement.
+type Toolchain struct {
+	Name   string // "go1.21rc1"
+	Syntax *Line
+}
+
+// A Godebug is a single godebug key=value statement.
+type Godebug struct {
+	Key    string
+	Value  string
+	Syntax *Line
+}
+
 // A Require is a single require statement.
 type Require struct {
 	Mod      module.Version
@@ -61,6 +81,33 @@ type Replace struct {
 	Syntax *Line
 }
 
+// A Retract is a single retract statement.
+type Retract struct {
+	VersionInterval
+	Rationale string
+	Syntax    *Line
+}
+
+// A VersionInterval represents a range of versions with upper and lower bounds.
+// Intervals are closed: both bounds are included. When Low is equal to High,
+// the interval may refer to a single version ('v1.2.3') or an interval
+// ('[v1.2.3, v1.2.3]'); both have the same representation.
+type VersionInterval struct {
+	Low, High string
+}
+
+// A Tool is a single tool statement.
+type Tool struct {
+	Path   string
+	Syntax *Line
+}
+
+// An Ignore is a single ignore statement.
+type Ignore struct {
+	Path   string
+	Syntax *Line
+}
+
 func (f *File) AddModuleStmt(path string) error {
 	if f.Syntax == nil {
 		f.Syntax = new(FileSyntax)
@@ -111,11 +158,46 @@ func ParseLax(file string, data []byte, fix VersionFixer) (*File, error) {
 	return parseToFile(file, data, fix, false)
 }
 
+// ParseUpstream is like Parse but ignores unknown statements and blocks. Unlike ParseLax, it keeps the replace and
+// exclude directives, it is used when the go.mod files of other modules are read for the versions they use.
+func ParseUpstream(file string, data []byte, fix VersionFixer) (*File, error) {
+	fs, err := parse(file, data)
+	if err != nil {
+		return nil, err
+	}
+	stmts := []Expr{}
+	for _, x := range fs.Stmt {
+		switch x := x.(type) {
+		case *Line:
+			if !knownDirectives[x.Token[0]] {
+				continue
+			}
+		case *LineBlock:
+			if len(x.Token) > 1 || !knownBlocks[x.Token[0]] {
+				continue
+			}
+		}
+		stmts = append(stmts, x)
+	}
+	fs.Stmt = stmts
+	return syntaxToFile(file, fs, fix, true)
+}
+
+// knownDirectives and knownBlocks are the statements the parser understand.
+var (
+	knownDirectives = map[string]bool{"module": true, "go": true, "toolchain": true, "godebug": true, "require": true, "exclude": true, "replace": true, "retract": true, "tool": true, "ignore": true}
+	knownBlocks     = map[string]bool{"module": true, "godebug": true, "require": true, "exclude": true, "replace": true, "retract": true, "tool": true, "ignore": true}
+)
+
 func parseToFile(file string, data []byte, fix VersionFixer, strict bool) (*File, error) {
 	fs, err := parse(file, data)
 	if err != nil {
 		return nil, err
 	}
+	return syntaxToFile(file, fs, fix, strict)
+}
+
+func syntaxToFile(file string, fs *FileSyntax, fix VersionFixer, strict bool) (*File, error) {
 	f := &File{
 		Syntax: fs,
 	}
@@ -124,7 +206,7 @@ func parseToFile(file string, data []byte, fix VersionFixer, strict bool) (*File
 	for _, x := range fs.Stmt {
 		switch x := x.(type) {
 		case *Line:
-			f.add(&errs, x, x.Token[0], x.Token[1:], fix, strict)
+			f.add(&errs, nil, x, x.Token[0], x.Token[1:], fix, strict)
 
 		case *LineBlock:
 			if len(x.Token) > 1 {
@@ -139,9 +221,9 @@ func parseToFile(file string, data []byte, fix VersionFixer, strict bool) (*File
 					fmt.Fprintf(&errs, "%s:%d: unknown block type: %s\n", file, x.Start.Line, strings.Join(x.Token, " "))
 				}
 				continue
-			case "module", "require", "exclude", "replace":
+			case "module", "godebug", "require", "exclude", "replace", "retract", "tool", "ignore":
 				for _, l := range x.Line {
-					f.add(&errs, l, x.Token[0], l.Token, fix, strict)
+					f.add(&errs, x, l, x.Token[0], l.Token, fix, strict)
 				}
 			}
 		}
@@ -153,9 +235,12 @@ func parseToFile(file string, data []byte, fix VersionFixer, strict bool) (*File
 	return f, nil
 }
 
-var GoVersionRE = lazyregexp.New(`^([1-9][0-9]*)\.(0|[1-9][0-9]*)$`)
+var GoVersionRE = lazyregexp.New(`^([1-9][0-9]*)\.(0|[1-9][0-9]*)(\.(0|[1-9][0-9]*))?([a-z]+[0-9]+)?$`)
+
+// ToolchainRE matches the toolchain names the go command accepts (go1.21.0, go1.21rc1, default).
+var ToolchainRE = lazyregexp.New(`^default$|^go1($|\.)`)
 
-func (f *File) add(errs *bytes.Buffer, line *Line, verb string, args []string, fix VersionFixer, strict bool) {
+func (f *File) add(errs *bytes.Buffer, block *LineBlock, line *Line, verb string, args []string, fix VersionFixer, strict bool) {
 	// If strict is false, this module is a dependency.
 	// We ignore all unknown directives as well as main-module-only
 	// directives like replace and exclude. It will work better for
@@ -164,7 +249,7 @@ func (f *File) add(errs *bytes.Buffer, line *Line, verb string, args []string, f
 	// and simply ignore those statements.
 	if !strict {
 		switch verb {
-		case "module", "require", "go":
+		case "module", "require", "go", "retract":
 			// want these even for dependency go.mods
 		default:
 			return
@@ -186,6 +271,20 @@ func (f *File) add(errs *bytes.Buffer, line *Line, verb string, args []string, f
 		}
 		f.Go = &Go{Syntax: line}
 		f.Go.Version = args[0]
+	case "toolchain":
+		if f.Toolchain != nil {
+			fmt.Fprintf(errs, "%s:%d: repeated toolchain statement\n", f.Syntax.Name, line.Start.Line)
+			return
+		}
+		if len(args) != 1 || !ToolchainRE.MatchString(args[0]) {
+			fmt.Fprintf(errs, "%s:%d: usage: toolchain go1.23.4\n", f.Syntax.Name, line.Start.Line)
+			return
+		}
+		f.Toolchain = &Toolchain{Name: args[0], Syntax: line}
+	case "godebug":
+		if g := parseGodebug(errs, f.Syntax.Name, line, args); g != nil {
+			f.Godebug = append(f.Godebug, g)
+		}
 	case "module":
 		if f.Module != nil {
 			fmt.Fprintf(errs, "%s:%d: repeated module statement\n", f.Syntax.Name, line.Start.Line)
@@ -203,6 +302,7 @@ func (f *File) add(errs *bytes.Buffer, line *Line, verb string, args []string, f
 			return
 		}
 		f.Module.Mod = module.Version{Path: s}
+		f.Module.Deprecated = parseDeprecation(block, line)
 	case "require", "exclude":
 		if len(args) != 2 {
 			fmt.Fprintf(errs, "%s:%d: usage: %s module/path v1.2.3\n", f.Syntax.Name, line.Start.Line, verb)
@@ -240,12 +340,29 @@ func (f *File) add(errs *bytes.Buffer, line *Line, verb string, args []string, f
 			})
 		}
 	case "replace":
-		arrow := 2
-		if len(args) >= 2 && args[1] == "=>" {
-			arrow = 1
+		if r := parseReplace(errs, f.Syntax.Name, line, verb, args, fix); r != nil {
+			f.Replace = append(f.Replace, r)
+		}
+	case "retract":
+		modulePath := ""
+		if f.Module != nil {
+			modulePath = f.Module.Mod.Path
+		}
+		vi, err := parseVersionInterval(verb, modulePath, args, fix)
+		if err != nil {
+			if strict {
+				fmt.Fprintf(errs, "%s:%d: %v\n", f.Syntax.Name, line.Start.Line, err)
+			}
+			return
 		}
-		if len(args) < arrow+2 || len(args) > arrow+3 || args[arrow] != "=>" {
-			fmt.Fprintf(errs, "%s:%d: usage: %s module/path [v1.2.3] => other/module v1.4\n\t or %s module/path [v1.2.3] => ../local/directory\n", f.Syntax.Name, line.Start.Line, verb, verb)
+		f.Retract = append(f.Retract, &Retract{
+			VersionInterval: vi,
+			Rationale:       parseDirectiveComment(block, line),
+			Syntax:          line,
+		})
+	case "tool", "ignore":
+		if len(args) != 1 {
+			fmt.Fprintf(errs, "%s:%d: usage: %s path\n", f.Syntax.Name, line.Start.Line, verb)
 			return
 		}
 		s, err := parseString(&args[0])
@@ -253,55 +370,178 @@ func (f *File) add(errs *bytes.Buffer, line *Line, verb string, args []string, f
 			fmt.Fprintf(errs, "%s:%d: invalid quoted string: %v\n", f.Syntax.Name, line.Start.Line, err)
 			return
 		}
-		pathMajor, err := modulePathMajor(s)
+		if verb == "tool" {
+			f.Tool = append(f.Tool, &Tool{Path: s, Syntax: line})
+		} else {
+			f.Ignore = append(f.Ignore, &Ignore{Path: s, Syntax: line})
+		}
+	}
+}
+
+// parseGodebug parses the godebug key=value statement, shared by go.mod and go.work files.
+// Errors are written to errs and nil is returned.
+func parseGodebug(errs *bytes.Buffer, filename string, line *Line, args []string) *Godebug {
+	if len(args) != 1 {
+		fmt.Fprintf(errs, "%s:%d: usage: godebug key=value\n", filename, line.Start.Line)
+		return nil
+	}
+	key, value, ok := cut(args[0], "=")
+	if !ok || strings.ContainsAny(key, "\"`',") || strings.ContainsAny(value, "\"`',") {
+		fmt.Fprintf(errs, "%s:%d: usage: godebug key=value\n", filename, line.Start.Line)
+		return nil
+	}
+	return &Godebug{Key: key, Value: value, Syntax: line}
+}
+
+func cut(s, sep string) (before, after string, found bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+// parseVersionInterval parses the retract arguments, either a single version
+// or an interval written as [v1.0.0, v1.1.0].
+func parseVersionInterval(verb string, path string, args []string, fix VersionFixer) (VersionInterval, error) {
+	if len(args) == 0 {
+		return VersionInterval{}, fmt.Errorf("expected '[' or version")
+	}
+	joined := strings.Join(args, "")
+	if !strings.HasPrefix(joined, "[") {
+		if len(args) != 1 {
+			return VersionInterval{}, fmt.Errorf("unexpected token after version: %q", args[1])
+		}
+		v, err := parseVersion(verb, path, &args[0], fix)
 		if err != nil {
-			fmt.Fprintf(errs, "%s:%d: %v\n", f.Syntax.Name, line.Start.Line, err)
-			return
+			return VersionInterval{}, err
 		}
-		var v string
-		if arrow == 2 {
-			v, err = parseVersion(verb, s, &args[1], fix)
-			if err != nil {
-				fmt.Fprintf(errs, "%s:%d: %v\n", f.Syntax.Name, line.Start.Line, err)
-				return
-			}
-			if err := module.CheckPathMajor(v, pathMajor); err != nil {
-				fmt.Fprintf(errs, "%s:%d: %v\n", f.Syntax.Name, line.Start.Line, &Error{Verb: verb, ModPath: s, Err: err})
-				return
+		return VersionInterval{Low: v, High: v}, nil
+	}
+	if !strings.HasSuffix(joined, "]") {
+		return VersionInterval{}, fmt.Errorf("expected ']' at the end of version interval")
+	}
+	bounds := strings.Split(strings.TrimSuffix(strings.TrimPrefix(joined, "["), "]"), ",")
+	if len(bounds) != 2 {
+		return VersionInterval{}, fmt.Errorf("expected version interval [low, high]")
+	}
+	low, err := parseVersion(verb, path, &bounds[0], fix)
+	if err != nil {
+		return VersionInterval{}, err
+	}
+	high, err := parseVersion(verb, path, &bounds[1], fix)
+	if err != nil {
+		return VersionInterval{}, err
+	}
+	if semver.Compare(low, high) > 0 {
+		return VersionInterval{}, fmt.Errorf("version %q is greater than version %q in interval", low, high)
+	}
+	return VersionInterval{Low: low, High: high}, nil
+}
+
+var deprecatedRE = lazyregexp.New(`(?s)(?:^|\n\n)Deprecated: *(.*?)(?:$|\n\n)`)
+
+// parseDeprecation extracts the text of comments on a "module" directive and
+// extracts a deprecation message from that.
+//
+// A deprecation message is contained in a paragraph within a block of comments
+// that starts with "Deprecated:" (case sensitive). The message runs until the
+// end of the paragraph and does not include the "Deprecated:" prefix. If the
+// comment block has multiple paragraphs that start with "Deprecated:",
+// parseDeprecation returns the message from the first.
+func parseDeprecation(block *LineBlock, line *Line) string {
+	text := parseDirectiveComment(block, line)
+	m := deprecatedRE.FindStringSubmatch(text)
+	if m == nil {
+		return ""
+	}
+	return m[1]
+}
+
+// parseDirectiveComment extracts the text of comments on a directive.
+// If the directive's line does not have comments and is part of a block that
+// does have comments, the block's comments are used.
+func parseDirectiveComment(block *LineBlock, line *Line) string {
+	comments := line.Comment()
+	if block != nil && len(comments.Before) == 0 && len(comments.Suffix) == 0 {
+		comments = block.Comment()
+	}
+	groups := [][]Comment{comments.Before, comments.Suffix}
+	var lines []string
+	for _, g := range groups {
+		for _, c := range g {
+			if !strings.HasPrefix(c.Token, "//") {
+				continue // blank line
 			}
+			lines = append(lines, strings.TrimSpace(strings.TrimPrefix(c.Token, "//")))
 		}
-		ns, err := parseString(&args[arrow+1])
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseReplace parses the replace statement arguments, shared by go.mod and go.work files.
+// Errors are written to errs and nil is returned.
+func parseReplace(errs *bytes.Buffer, filename string, line *Line, verb string, args []string, fix VersionFixer) *Replace {
+	arrow := 2
+	if len(args) >= 2 && args[1] == "=>" {
+		arrow = 1
+	}
+	if len(args) < arrow+2 || len(args) > arrow+3 || args[arrow] != "=>" {
+		fmt.Fprintf(errs, "%s:%d: usage: %s module/path [v1.2.3] => other/module v1.4\n\t or %s module/path [v1.2.3] => ../local/directory\n", filename, line.Start.Line, verb, verb)
+		return nil
+	}
+	s, err := parseString(&args[0])
+	if err != nil {
+		fmt.Fprintf(errs, "%s:%d: invalid quoted string: %v\n", filename, line.Start.Line, err)
+		return nil
+	}
+	pathMajor, err := modulePathMajor(s)
+	if err != nil {
+		fmt.Fprintf(errs, "%s:%d: %v\n", filename, line.Start.Line, err)
+		return nil
+	}
+	var v string
+	if arrow == 2 {
+		v, err = parseVersion(verb, s, &args[1], fix)
 		if err != nil {
-			fmt.Fprintf(errs, "%s:%d: invalid quoted string: %v\n", f.Syntax.Name, line.Start.Line, err)
-			return
+			fmt.Fprintf(errs, "%s:%d: %v\n", filename, line.Start.Line, err)
+			return nil
 		}
-		nv := ""
-		if len(args) == arrow+2 {
-			if !IsDirectoryPath(ns) {
-				fmt.Fprintf(errs, "%s:%d: replacement module without version must be directory path (rooted or starting with ./ or ../)\n", f.Syntax.Name, line.Start.Line)
-				return
-			}
-			if filepath.Separator == '/' && strings.Contains(ns, `\`) {
-				fmt.Fprintf(errs, "%s:%d: replacement directory appears to be Windows path (on a non-windows system)\n", f.Syntax.Name, line.Start.Line)
-				return
-			}
+		if err := module.CheckPathMajor(v, pathMajor); err != nil {
+			fmt.Fprintf(errs, "%s:%d: %v\n", filename, line.Start.Line, &Error{Verb: verb, ModPath: s, Err: err})
+			return nil
 		}
-		if len(args) == arrow+3 {
-			nv, err = parseVersion(verb, ns, &args[arrow+2], fix)
-			if err != nil {
-				fmt.Fprintf(errs, "%s:%d: %v\n", f.Syntax.Name, line.Start.Line, err)
-				return
-			}
-			if IsDirectoryPath(ns) {
-				fmt.Fprintf(errs, "%s:%d: replacement module directory path %q cannot have version\n", f.Syntax.Name, line.Start.Line, ns)
-				return
-			}
+	}
+	ns, err := parseString(&args[arrow+1])
+	if err != nil {
+		fmt.Fprintf(errs, "%s:%d: invalid quoted string: %v\n", filename, line.Start.Line, err)
+		return nil
+	}
+	nv := ""
+	if len(args) == arrow+2 {
+		if !IsDirectoryPath(ns) {
+			fmt.Fprintf(errs, "%s:%d: replacement module without version must be directory path (rooted or starting with ./ or ../)\n", filename, line.Start.Line)
+			return nil
+		}
+		if filepath.Separator == '/' && strings.Contains(ns, `\`) {
+			fmt.Fprintf(errs, "%s:%d: replacement directory appears to be Windows path (on a non-windows system)\n", filename, line.Start.Line)
+			return nil
+		}
+	}
+	if len(args) == arrow+3 {
+		nv, err = parseVersion(verb, ns, &args[arrow+2], fix)
+		if err != nil {
+			fmt.Fprintf(errs, "%s:%d: %v\n", filename, line.Start.Line, err)
+			return nil
+		}
+		if IsDirectoryPath(ns) {
+			fmt.Fprintf(errs, "%s:%d: replacement module directory path %q cannot have version\n", filename, line.Start.Line, ns)
+			return nil
 		}
-		f.Replace = append(f.Replace, &Replace{
-			Old:    module.Version{Path: s, Version: v},
-			New:    module.Version{Path: ns, Version: nv},
-			Syntax: line,
-		})
+	}
+	return &Replace{
+		Old:    module.Version{Path: s, Version: v},
+		New:    module.Version{Path: ns, Version: nv},
+		Syntax: line,
 	}
 }
 
@@ -497,6 +737,42 @@ func (f *File) Cleanup() {
 	}
 	f.Replace = f.Replace[:w]
 
+	w = 0
+	for _, g := range f.Godebug {
+		if g.Key != "" {
+			f.Godebug[w] = g
+			w++
+		}
+	}
+	f.Godebug = f.Godebug[:w]
+
+	w = 0
+	for _, r := range f.Retract {
+		if r.Low != "" || r.High != "" {
+			f.Retract[w] = r
+			w++
+		}
+	}
+	f.Retract = f.Retract[:w]
+
+	w = 0
+	for _, t := range f.Tool {
+		if t.Path != "" {
+			f.Tool[w] = t
+			w++
+		}
+	}
+	f.Tool = f.Tool[:w]
+
+	w = 0
+	for _, i := range f.Ignore {
+		if i.Path != "" {
+			f.Ignore[w] = i
+			w++
+		}
+	}
+	f.Ignore = f.Ignore[:w]
+
 	f.Syntax.Cleanup()
 }
 
@@ -516,6 +792,83 @@ func (f *File) AddGoStmt(version string) error {
 	return nil
 }
 
+// AddToolchainStmt sets f.Toolchain to the given toolchain name.
+// If f.Toolchain is already set, AddToolchainStmt updates it.
+func (f *File) AddToolchainStmt(name string) error {
+	if !ToolchainRE.MatchString(name) {
+		return fmt.Errorf("invalid toolchain name %q", name)
+	}
+	if f.Toolchain == nil {
+		var hint Expr
+		if f.Go != nil && f.Go.Syntax != nil {
+			hint = f.Go.Syntax
+		}
+		f.Toolchain = &Toolchain{
+			Name:   name,
+			Syntax: f.Syntax.addLine(hint, "toolchain", name),
+		}
+	} else {
+		f.Toolchain.Name = name
+		f.Syntax.updateLine(f.Toolchain.Syntax, "toolchain", name)
+	}
+	return nil
+}
+
+// DropToolchainStmt deletes the toolchain statement from the file.
+func (f *File) DropToolchainStmt() {
+	if f.Toolchain != nil {
+		f.Syntax.removeLine(f.Toolchain.Syntax)
+		f.Toolchain = nil
+	}
+}
+
+// AddGodebug sets the first godebug line for key to value,
+// preserving any existing comments for that line and removing all
+// other godebug lines for key.
+//
+// If no line currently exists for key, AddGodebug adds a new line
+// at the end of the last godebug block.
+func (f *File) AddGodebug(key, value string) error {
+	return addGodebug(f.Syntax, &f.Godebug, key, value)
+}
+
+// DropGodebug removes the first godebug with the given key.
+func (f *File) DropGodebug(key string) error {
+	dropGodebug(f.Syntax, f.Godebug, key)
+	return nil
+}
+
+func addGodebug(syntax *FileSyntax, godebug *[]*Godebug, key, value string) error {
+	need := true
+	for _, g := range *godebug {
+		if g.Key == key {
+			if need {
+				g.Value = value
+				syntax.updateLine(g.Syntax, "godebug", key+"="+value)
+				need = false
+			} else {
+				syntax.removeLine(g.Syntax)
+				*g = Godebug{}
+			}
+		}
+	}
+
+	if need {
+		line := syntax.addLine(nil, "godebug", key+"="+value)
+		*godebug = append(*godebug, &Godebug{Key: key, Value: value, Syntax: line})
+	}
+	return nil
+}
+
+func dropGodebug(syntax *FileSyntax, godebug []*Godebug, key string) {
+	for _, g := range godebug {
+		if g.Key == key {
+			syntax.removeLine(g.Syntax)
+			*g = Godebug{}
+		}
+	}
+}
+
 func (f *File) AddRequire(path, vers string) error {
 	need := true
 	for _, r := range f.Require {
@@ -638,6 +991,11 @@ func (f *File) DropExclude(path, vers string) error {
 }
 
 func (f *File) AddReplace(oldPath, oldVers, newPath, newVers string) error {
+	return addReplace(f.Syntax, &f.Replace, oldPath, oldVers, newPath, newVers)
+}
+
+// addReplace add or update the replace statement in the replace list and syntax, shared by go.mod and go.work files.
+func addReplace(syntax *FileSyntax, replace *[]*Replace, oldPath, oldVers, newPath, newVers string) error {
 	need := true
 	old := module.Version{Path: oldPath, Version: oldVers}
 	new := module.Version{Path: newPath, Version: newVers}
@@ -651,17 +1009,17 @@ func (f *File) AddReplace(oldPath, oldVers, newPath, newVers string) error {
 	}
 
 	var hint *Line
-	for _, r := range f.Replace {
+	for _, r := range *replace {
 		if r.Old.Path == oldPath && (oldVers == "" || r.Old.Version == oldVers) {
 			if need {
 				// Found replacement for old; update to use new.
 				r.New = new
-				f.Syntax.updateLine(r.Syntax, tokens...)
+				syntax.updateLine(r.Syntax, tokens...)
 				need = false
 				continue
 			}
 			// Already added; delete other replacements for same.
-			f.Syntax.removeLine(r.Syntax)
+			syntax.removeLine(r.Syntax)
 			*r = Replace{}
 		}
 		if r.Old.Path == oldPath {
@@ -669,7 +1027,7 @@ func (f *File) AddReplace(oldPath, oldVers, newPath, newVers string) error {
 		}
 	}
 	if need {
-		f.Replace = append(f.Replace, &Replace{Old: old, New: new, Syntax: f.Syntax.addLine(hint, tokens...)})
+		*replace = append(*replace, &Replace{Old: old, New: new, Syntax: syntax.addLine(hint, tokens...)})
 	}
 	return nil
 }
@@ -684,6 +1042,109 @@ func (f *File) DropReplace(oldPath, oldVers string) error {
 	return nil
 }
 
+// DropReplaceStmt removes the single replace statement r, keeping other
+// replacements for the same module path and version.
+func (f *File) DropReplaceStmt(r *Replace) {
+	f.Syntax.removeLine(r.Syntax)
+	*r = Replace{}
+}
+
+// AddRetract adds a retract statement to the mod file. Params low and high
+// are the boundaries of the versions to retract, they are the same when a single
+// version is retracted. The rationale is written as a suffix comment.
+func (f *File) AddRetract(vi VersionInterval, rationale string) error {
+	var path string
+	if f.Module != nil {
+		path = f.Module.Mod.Path
+	}
+	args := []string{vi.Low}
+	if vi.Low != vi.High {
+		args = []string{"[" + vi.Low + ",", vi.High + "]"}
+	}
+	if _, err := parseVersionInterval("retract", path, append([]string{}, args...), nil); err != nil {
+		return err
+	}
+	r := &Retract{
+		VersionInterval: vi,
+		Syntax:          f.Syntax.addLine(nil, append([]string{"retract"}, args...)...),
+	}
+	if rationale != "" {
+		for _, line := range strings.Split(rationale, "\n") {
+			com := Comment{Token: "// " + line}
+			r.Syntax.Comment().Before = append(r.Syntax.Comment().Before, com)
+		}
+		r.Rationale = rationale
+	}
+	f.Retract = append(f.Retract, r)
+	return nil
+}
+
+func (f *File) DropRetract(vi VersionInterval) error {
+	for _, r := range f.Retract {
+		if r.VersionInterval == vi {
+			f.Syntax.removeLine(r.Syntax)
+			*r = Retract{}
+		}
+	}
+	return nil
+}
+
+// AddTool adds a new tool directive with the given path.
+// It does nothing if the tool line already exists.
+func (f *File) AddTool(path string) error {
+	for _, t := range f.Tool {
+		if t.Path == path {
+			return nil
+		}
+	}
+
+	f.Tool = append(f.Tool, &Tool{
+		Path:   path,
+		Syntax: f.Syntax.addLine(nil, "tool", AutoQuote(path)),
+	})
+	return nil
+}
+
+// DropTool removes a tool directive with the given path.
+// It does nothing if no such tool directive exists.
+func (f *File) DropTool(path string) error {
+	for _, t := range f.Tool {
+		if t.Path == path {
+			f.Syntax.removeLine(t.Syntax)
+			*t = Tool{}
+		}
+	}
+	return nil
+}
+
+// AddIgnore adds a new ignore directive with the given path.
+// It does nothing if the ignore line already exists.
+func (f *File) AddIgnore(path string) error {
+	for _, i := range f.Ignore {
+		if i.Path == path {
+			return nil
+		}
+	}
+
+	f.Ignore = append(f.Ignore, &Ignore{
+		Path:   path,
+		Syntax: f.Syntax.addLine(nil, "ignore", AutoQuote(path)),
+	})
+	return nil
+}
+
+// DropIgnore removes an ignore directive with the given path.
+// It does nothing if no such ignore directive exists.
+func (f *File) DropIgnore(path string) error {
+	for _, i := range f.Ignore {
+		if i.Path == path {
+			f.Syntax.removeLine(i.Syntax)
+			*i = Ignore{}
+		}
+	}
+	return nil
+}
+
 func (f *File) SortBlocks() {
 	f.removeDups() // otherwise sorting is unsafe
 
@@ -4,4 +4,19 @@ import (
 	"github.com/mfojtik/goodmod/pkg/golang/internal/modfile"
 )
 
-var ParseModFile = modfile.Parse
+type (
+	ModFile  = modfile.File
+	Replace  = modfile.Replace
+	Require  = modfile.Require
+	WorkFile = modfile.WorkFile
+	Use      = modfile.Use
+	Line     = modfile.Line
+	Comment  = modfile.Comment
+)
+
+var (
+	ParseModFile         = modfile.Parse
+	ParseModFileLax      = modfile.ParseLax
+	ParseUpstreamModFile = modfile.ParseUpstream
+	ParseWorkFile        = modfile.ParseWork
+)
@@ -0,0 +1,129 @@
+package golang
+
+import (
+	"reflect"
+	"testing"
+)
+
+const testModernGoMod = `// Deprecated: use example.com/mod/v2 instead.
+module example.com/mod
+
+go 1.22.1
+
+toolchain go1.23.4
+
+godebug (
+	default=go1.21
+	panicnil=1
+)
+
+require (
+	golang.org/x/tools v0.20.0 // indirect
+	k8s.io/api v0.30.0
+)
+
+replace k8s.io/api => k8s.io/api v0.30.1
+
+retract (
+	// Published accidentally.
+	v1.0.0
+	[v1.1.0, v1.1.5] // Broken build.
+)
+
+tool golang.org/x/tools/cmd/stringer
+
+ignore ./testdata
+`
+
+func TestParseModernGoMod(t *testing.T) {
+	f, err := ParseModFile("go.mod", []byte(testModernGoMod), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Module.Deprecated != "use example.com/mod/v2 instead." {
+		t.Errorf("unexpected deprecation: %q", f.Module.Deprecated)
+	}
+	if f.Go.Version != "1.22.1" || f.Toolchain.Name != "go1.23.4" {
+		t.Errorf("unexpected go %q or toolchain %q", f.Go.Version, f.Toolchain.Name)
+	}
+	if len(f.Godebug) != 2 || f.Godebug[1].Key != "panicnil" || f.Godebug[1].Value != "1" {
+		t.Errorf("unexpected godebug: %#v", f.Godebug)
+	}
+	if len(f.Retract) != 2 || f.Retract[0].Rationale != "Published accidentally." ||
+		f.Retract[1].Low != "v1.1.0" || f.Retract[1].High != "v1.1.5" || f.Retract[1].Rationale != "Broken build." {
+		t.Errorf("unexpected retract: %#v %#v", f.Retract[0], f.Retract[1])
+	}
+	if len(f.Tool) != 1 || f.Tool[0].Path != "golang.org/x/tools/cmd/stringer" || len(f.Ignore) != 1 || f.Ignore[0].Path != "./testdata" {
+		t.Errorf("unexpected tool %#v or ignore %#v", f.Tool, f.Ignore)
+	}
+	out, err := f.Format()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != testModernGoMod {
+		t.Errorf("expected lossless round-trip, got:\n%s", out)
+	}
+
+	if err := f.AddReplace("k8s.io/api", "", "k8s.io/api", "v0.30.2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.AddToolchainStmt("go1.24.0"); err != nil {
+		t.Fatal(err)
+	}
+	f.Cleanup()
+	out, err = f.Format()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseModFile("go.mod", out, nil); err != nil {
+		t.Errorf("unable to parse edited go.mod: %v\n%s", err, out)
+	}
+}
+
+func TestParseUnknownDirectives(t *testing.T) {
+	content := []byte("module example.com/mod\n\ngo 1.30\n\nfuture example.com/x\n\nfuture (\n\tsomething\n)\n")
+	if _, err := ParseModFile("go.mod", content, nil); err == nil {
+		t.Error("expected strict parse to fail on unknown directive")
+	}
+	f, err := ParseModFileLax("go.mod", content, nil)
+	if err != nil {
+		t.Fatalf("expected lax parse to tolerate unknown directives: %v", err)
+	}
+	if f.Go.Version != "1.30" {
+		t.Errorf("unexpected go version %q", f.Go.Version)
+	}
+}
+
+func TestUpstreamVersions(t *testing.T) {
+	content := []byte(`module example.com/upstream
+
+go 1.30
+
+future example.com/x
+
+future (
+	something
+)
+
+require (
+	example.com/a v1.0.0
+	example.com/b v1.2.0
+)
+
+replace example.com/b => example.com/fork/b v1.2.1
+`)
+	if _, err := RequiredVersions("go.mod", content); err == nil {
+		t.Error("expected required versions to fail on unknown directive")
+	}
+	modules, err := UpstreamVersions("go.mod", content)
+	if err != nil {
+		t.Fatalf("expected unknown directives to be ignored: %v", err)
+	}
+	expected := map[string]ModuleVersion{
+		"example.com/a": {Path: "example.com/a", Version: "v1.0.0"},
+		"example.com/b": {Path: "example.com/b", Version: "v1.2.0", ReplacePath: "example.com/fork/b", ReplaceVersion: "v1.2.1"},
+	}
+	if !reflect.DeepEqual(modules, expected) {
+		t.Errorf("expected %#v, got %#v", expected, modules)
+	}
+}
@@ -0,0 +1,80 @@
+package golang
+
+import (
+	"regexp"
+
+	"github.com/mfojtik/goodmod/pkg/golang/internal/modfile"
+	"github.com/mfojtik/goodmod/pkg/golang/internal/module"
+	"github.com/mfojtik/goodmod/pkg/golang/internal/semver"
+)
+
+var (
+	CompareVersions = semver.Compare
+	EscapePath      = module.EscapePath
+	EscapeVersion   = module.EscapeVersion
+	IsDirectoryPath = modfile.IsDirectoryPath
+	AutoQuote       = modfile.AutoQuote
+)
+
+var pseudoVersionRE = regexp.MustCompile(`[-.][0-9]{14}-([0-9a-f]{12})(\+incompatible)?$`)
+
+// PseudoVersionCommit return the abbreviated commit SHA from pseudo-version (v0.0.0-20191016115129-c07a134afb42).
+func PseudoVersionCommit(version string) (string, bool) {
+	parts := pseudoVersionRE.FindStringSubmatch(version)
+	if parts == nil {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// ModuleVersion is a version of module required by a go.mod file.
+type ModuleVersion struct {
+	Path    string
+	Version string
+
+	// ReplacePath and ReplaceVersion are set when the go.mod file replace the module
+	ReplacePath    string
+	ReplaceVersion string
+}
+
+// Target return the module path and version the go.mod file effectively use for this module.
+func (m ModuleVersion) Target() (string, string) {
+	if len(m.ReplacePath) > 0 {
+		return m.ReplacePath, m.ReplaceVersion
+	}
+	return m.Path, m.Version
+}
+
+// RequiredVersions parse the go.mod file content and return all required and replaced modules keyed by module path.
+func RequiredVersions(file string, data []byte) (map[string]ModuleVersion, error) {
+	f, err := ParseModFile(file, data, nil)
+	if err != nil {
+		return nil, err
+	}
+	return moduleVersions(f), nil
+}
+
+// UpstreamVersions is like RequiredVersions, but the directives it does not understand are ignored. It is used for
+// go.mod files of other modules (eg. the anchor or the sync source) that might use directives of newer Go versions.
+func UpstreamVersions(file string, data []byte) (map[string]ModuleVersion, error) {
+	f, err := ParseUpstreamModFile(file, data, nil)
+	if err != nil {
+		return nil, err
+	}
+	return moduleVersions(f), nil
+}
+
+func moduleVersions(f *ModFile) map[string]ModuleVersion {
+	result := map[string]ModuleVersion{}
+	for _, r := range f.Require {
+		result[r.Mod.Path] = ModuleVersion{Path: r.Mod.Path, Version: r.Mod.Version}
+	}
+	for _, r := range f.Replace {
+		m := result[r.Old.Path]
+		m.Path = r.Old.Path
+		m.ReplacePath = r.New.Path
+		m.ReplaceVersion = r.New.Version
+		result[r.Old.Path] = m
+	}
+	return result
+}
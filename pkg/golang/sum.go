@@ -0,0 +1,96 @@
+package golang
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+)
+
+const goModSuffix = "/go.mod"
+
+type sumLine struct {
+	path    string
+	version string
+	hash    string
+}
+
+// GoSum is the content of go.sum file.
+type GoSum struct {
+	lines []sumLine
+}
+
+// ReadGoSum read the go.sum file. Empty GoSum is returned when the file does not exists.
+func ReadGoSum(file string) (*GoSum, error) {
+	content, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return &GoSum{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	s := &GoSum{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("%s:%d: malformed go.sum line", file, lineNum)
+		}
+		s.lines = append(s.lines, sumLine{path: fields[0], version: fields[1], hash: fields[2]})
+	}
+	return s, scanner.Err()
+}
+
+// Set set the hash of module version. Use version with "/go.mod" suffix to set the go.mod file hash.
+func (s *GoSum) Set(path, version, hash string) {
+	for i := range s.lines {
+		if s.lines[i].path == path && s.lines[i].version == version {
+			s.lines[i].hash = hash
+			return
+		}
+	}
+	s.lines = append(s.lines, sumLine{path: path, version: version, hash: hash})
+}
+
+// Drop remove both module and go.mod file hashes of the module version.
+func (s *GoSum) Drop(path, version string) {
+	lines := []sumLine{}
+	for _, l := range s.lines {
+		if l.path == path && (l.version == version || l.version == version+goModSuffix) {
+			continue
+		}
+		lines = append(lines, l)
+	}
+	s.lines = lines
+}
+
+// Bytes return the go.sum file content sorted the same way Go module tooling does.
+func (s *GoSum) Bytes() []byte {
+	sort.SliceStable(s.lines, func(i, j int) bool {
+		li, lj := s.lines[i], s.lines[j]
+		if li.path != lj.path {
+			return li.path < lj.path
+		}
+		vi, vj := strings.TrimSuffix(li.version, goModSuffix), strings.TrimSuffix(lj.version, goModSuffix)
+		if vi != vj {
+			return CompareVersions(vi, vj) < 0
+		}
+		return !strings.HasSuffix(li.version, goModSuffix) && strings.HasSuffix(lj.version, goModSuffix)
+	})
+	var buf bytes.Buffer
+	for _, l := range s.lines {
+		fmt.Fprintf(&buf, "%s %s %s\n", l.path, l.version, l.hash)
+	}
+	return buf.Bytes()
+}
+
+// Write write the go.sum file.
+func (s *GoSum) Write(file string) error {
+	return ioutil.WriteFile(file, s.Bytes(), 0644)
+}
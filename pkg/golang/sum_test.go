@@ -0,0 +1,35 @@
+package golang
+
+import (
+	"testing"
+)
+
+func TestHashGoMod(t *testing.T) {
+	// gopkg.in/yaml.v2 v2.2.2 go.mod file
+	goMod := "module \"gopkg.in/yaml.v2\"\n\nrequire (\n\t\"gopkg.in/check.v1\" v0.0.0-20161208181325-20d25e280405\n)\n"
+	hash, err := HashGoMod([]byte(goMod))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "h1:hI93XBmqTisBFMUTm0b8Fm+jr3Dg1NNxqwp+5A1VGuI="; hash != expected {
+		t.Errorf("expected %q, got %q", expected, hash)
+	}
+}
+
+func TestGoSumBytes(t *testing.T) {
+	s := &GoSum{}
+	s.Set("k8s.io/api", "v0.10.0/go.mod", "h1:b")
+	s.Set("k8s.io/api", "v0.9.0", "h1:c")
+	s.Set("k8s.io/api", "v0.10.0", "h1:a")
+	s.Set("github.com/openshift/api", "v0.0.0-20191016115129-c07a134afb42", "h1:d")
+	s.Set("k8s.io/api", "v0.9.0/go.mod", "h1:e")
+	s.Drop("k8s.io/api", "v0.9.0")
+
+	expected := `github.com/openshift/api v0.0.0-20191016115129-c07a134afb42 h1:d
+k8s.io/api v0.10.0 h1:a
+k8s.io/api v0.10.0/go.mod h1:b
+`
+	if got := string(s.Bytes()); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
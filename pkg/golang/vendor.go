@@ -0,0 +1,156 @@
+package golang
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/mfojtik/goodmod/pkg/golang/internal/modfile"
+)
+
+// VendorModule is a single module listed in vendor/modules.txt file.
+type VendorModule struct {
+	Path    string
+	Version string
+
+	ReplacePath    string
+	ReplaceVersion string
+
+	// Annotations are the "## ..." lines (eg. "## explicit; go 1.13") without the prefix
+	Annotations []string
+	// Packages are the vendored packages import paths
+	Packages []string
+}
+
+// VendorModules is the content of vendor/modules.txt file.
+type VendorModules struct {
+	Modules []*VendorModule
+}
+
+// ReadVendorModules read and parse the vendor/modules.txt file.
+func ReadVendorModules(file string) (*VendorModules, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	v := &VendorModules{}
+	var current *VendorModule
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "## "):
+			if current == nil {
+				return nil, fmt.Errorf("%s:%d: annotation without module", file, lineNum)
+			}
+			current.Annotations = append(current.Annotations, strings.TrimPrefix(line, "## "))
+		case strings.HasPrefix(line, "# "):
+			fields := strings.Fields(strings.TrimPrefix(line, "# "))
+			current = &VendorModule{}
+			target := fields
+			for i, f := range fields {
+				if f == "=>" {
+					target = fields[i+1:]
+					fields = fields[:i]
+					if len(target) == 0 || len(target) > 2 {
+						return nil, fmt.Errorf("%s:%d: malformed replacement", file, lineNum)
+					}
+					current.ReplacePath = target[0]
+					if len(target) == 2 {
+						current.ReplaceVersion = target[1]
+					}
+					break
+				}
+			}
+			if len(fields) == 0 || len(fields) > 2 {
+				return nil, fmt.Errorf("%s:%d: malformed module line", file, lineNum)
+			}
+			current.Path = fields[0]
+			if len(fields) == 2 {
+				current.Version = fields[1]
+			}
+			v.Modules = append(v.Modules, current)
+		case len(strings.TrimSpace(line)) == 0:
+			continue
+		default:
+			if current == nil {
+				return nil, fmt.Errorf("%s:%d: package without module", file, lineNum)
+			}
+			current.Packages = append(current.Packages, strings.TrimSpace(line))
+		}
+	}
+	return v, scanner.Err()
+}
+
+// Module return the vendored module with given path or nil when the module is not vendored.
+func (v *VendorModules) Module(path string) *VendorModule {
+	for _, m := range v.Modules {
+		if m.Path == path {
+			return m
+		}
+	}
+	return nil
+}
+
+// Bytes return the vendor/modules.txt file content.
+func (v *VendorModules) Bytes() []byte {
+	var buf bytes.Buffer
+	for _, m := range v.Modules {
+		line := strings.TrimSpace("# " + m.Path + " " + m.Version)
+		if len(m.ReplacePath) > 0 {
+			line = strings.TrimSpace(line + " => " + m.ReplacePath + " " + m.ReplaceVersion)
+		}
+		buf.WriteString(line + "\n")
+		for _, a := range m.Annotations {
+			buf.WriteString("## " + a + "\n")
+		}
+		for _, p := range m.Packages {
+			buf.WriteString(p + "\n")
+		}
+	}
+	return buf.Bytes()
+}
+
+// Write write the vendor/modules.txt file.
+func (v *VendorModules) Write(file string) error {
+	return ioutil.WriteFile(file, v.Bytes(), 0644)
+}
+
+// VendorMismatches return differences between versions and replacements in go.mod and vendor/modules.txt.
+// Modules that are required only indirectly (not listed in go.mod) are not checked.
+func VendorMismatches(goMod map[string]ModuleVersion, vendor *VendorModules) []string {
+	result := []string{}
+	for _, m := range vendor.Modules {
+		required, ok := goMod[m.Path]
+		if !ok {
+			continue
+		}
+		// replacement only lines does not list version
+		if len(m.Version) > 0 && len(required.Version) > 0 && m.Version != required.Version {
+			result = append(result, fmt.Sprintf("%s: go.mod requires %s, vendor/modules.txt has %s", m.Path, required.Version, m.Version))
+		}
+		if m.ReplacePath != required.ReplacePath || m.ReplaceVersion != required.ReplaceVersion {
+			result = append(result, fmt.Sprintf("%s: go.mod replacement %q, vendor/modules.txt has %q",
+				m.Path, strings.TrimSpace(required.ReplacePath+" "+required.ReplaceVersion), strings.TrimSpace(m.ReplacePath+" "+m.ReplaceVersion)))
+		}
+	}
+	for path, required := range goMod {
+		if len(required.ReplacePath) > 0 && vendor.Module(path) == nil {
+			result = append(result, fmt.Sprintf("%s: go.mod replacement %q is missing in vendor/modules.txt", path, strings.TrimSpace(required.ReplacePath+" "+required.ReplaceVersion)))
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// GoVersion return the Go language version set by go directive in go.mod content or empty string.
+func GoVersion(goMod []byte) string {
+	f, err := modfile.ParseLax("go.mod", goMod, nil)
+	if err != nil || f.Go == nil {
+		return ""
+	}
+	return f.Go.Version
+}
@@ -0,0 +1,55 @@
+package golang
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testModulesTxt = `# github.com/openshift/api v0.0.0-20191016115129-c07a134afb42
+## explicit
+github.com/openshift/api/config/v1
+# k8s.io/api v0.17.0 => k8s.io/api v0.18.0
+## explicit; go 1.13
+k8s.io/api/core/v1
+k8s.io/api/apps/v1
+# github.com/openshift/library-go => ../library-go
+`
+
+func TestVendorModules(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vendor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "modules.txt")
+	if err := ioutil.WriteFile(file, []byte(testModulesTxt), 0644); err != nil {
+		t.Fatal(err)
+	}
+	v, err := ReadVendorModules(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(v.Bytes()); got != testModulesTxt {
+		t.Errorf("expected lossless round-trip, got:\n%s", got)
+	}
+	api := v.Module("k8s.io/api")
+	if api == nil || api.ReplaceVersion != "v0.18.0" || len(api.Packages) != 2 || api.Annotations[0] != "explicit; go 1.13" {
+		t.Errorf("unexpected k8s.io/api module: %#v", api)
+	}
+
+	mismatches := VendorMismatches(map[string]ModuleVersion{
+		"github.com/openshift/api": {Path: "github.com/openshift/api", Version: "v0.0.0-20191016115129-c07a134afb42"},
+		"k8s.io/api":               {Path: "k8s.io/api", Version: "v0.17.0", ReplacePath: "k8s.io/api", ReplaceVersion: "v0.19.0"},
+		"k8s.io/apimachinery":      {Path: "k8s.io/apimachinery", Version: "v0.17.0", ReplacePath: "k8s.io/apimachinery", ReplaceVersion: "v0.19.0"},
+	}, v)
+	expected := []string{
+		`k8s.io/api: go.mod replacement "k8s.io/api v0.19.0", vendor/modules.txt has "k8s.io/api v0.18.0"`,
+		`k8s.io/apimachinery: go.mod replacement "k8s.io/apimachinery v0.19.0" is missing in vendor/modules.txt`,
+	}
+	if !reflect.DeepEqual(mismatches, expected) {
+		t.Errorf("expected %#v, got %#v", expected, mismatches)
+	}
+}
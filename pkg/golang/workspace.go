@@ -0,0 +1,90 @@
+package golang
+
+import (
+	"io/ioutil"
+	"path/filepath"
+)
+
+// WorkspaceModule is a module listed in the go.work file 'use' directive.
+type WorkspaceModule struct {
+	// Dir is the module directory as listed in go.work
+	Dir string
+	// GoModPath is the path to module go.mod file
+	GoModPath string
+	// Path is the module path
+	Path string
+	// Modules are the modules required and replaced by module go.mod file
+	Modules map[string]ModuleVersion
+}
+
+// ReadWorkspace read the go.work file and go.mod files of all modules it use.
+func ReadWorkspace(goWorkPath string) (*WorkFile, []WorkspaceModule, error) {
+	workBytes, err := ioutil.ReadFile(goWorkPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	work, err := ParseWorkFile(goWorkPath, workBytes, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	modules := []WorkspaceModule{}
+	for _, u := range work.Use {
+		dir := u.Path
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(filepath.Dir(goWorkPath), dir)
+		}
+		goModPath := filepath.Join(dir, "go.mod")
+		modBytes, err := ioutil.ReadFile(goModPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		f, err := ParseModFile(goModPath, modBytes, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		required, err := RequiredVersions(goModPath, modBytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		m := WorkspaceModule{Dir: u.Path, GoModPath: goModPath, Modules: required}
+		if f.Module != nil {
+			m.Path = f.Module.Mod.Path
+		}
+		modules = append(modules, m)
+	}
+	return work, modules, nil
+}
+
+// WorkspaceVersions return the modules the workspace require and replace keyed by module path. The highest version
+// required by any workspace module is used, replacements in go.work take priority over replacements in go.mod files.
+// The workspace modules themselves are not included.
+func WorkspaceVersions(work *WorkFile, modules []WorkspaceModule) map[string]ModuleVersion {
+	result := map[string]ModuleVersion{}
+	for _, wm := range modules {
+		for path, m := range wm.Modules {
+			current, ok := result[path]
+			if !ok {
+				result[path] = m
+				continue
+			}
+			if CompareVersions(m.Version, current.Version) > 0 {
+				current.Version = m.Version
+			}
+			if len(m.ReplacePath) > 0 {
+				current.ReplacePath, current.ReplaceVersion = m.ReplacePath, m.ReplaceVersion
+			}
+			result[path] = current
+		}
+	}
+	for _, r := range work.Replace {
+		m := result[r.Old.Path]
+		m.Path = r.Old.Path
+		m.ReplacePath = r.New.Path
+		m.ReplaceVersion = r.New.Version
+		result[r.Old.Path] = m
+	}
+	for _, wm := range modules {
+		delete(result, wm.Path)
+	}
+	return result
+}
@@ -0,0 +1,122 @@
+package golang
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testGoWork = `// workspace for local development
+go 1.18
+
+use (
+	./api
+	./operator // github.com/openshift/operator
+)
+
+replace k8s.io/api => k8s.io/api v0.19.0
+`
+
+func writeFile(t *testing.T, file, content string) {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWorkFileFormat(t *testing.T) {
+	f, err := ParseWorkFile("go.work", []byte(testGoWork), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Go.Version != "1.18" || len(f.Use) != 2 || len(f.Replace) != 1 {
+		t.Fatalf("unexpected go.work: %#v", f)
+	}
+	if f.Use[1].Path != "./operator" || f.Use[1].ModulePath != "github.com/openshift/operator" {
+		t.Errorf("unexpected use: %#v", f.Use[1])
+	}
+	out, err := f.Format()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != testGoWork {
+		t.Errorf("expected lossless round-trip, got:\n%s", out)
+	}
+
+	if err := f.AddReplace("k8s.io/api", "", "k8s.io/api", "v0.20.0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.AddReplace("k8s.io/client-go", "", "k8s.io/client-go", "v0.20.0"); err != nil {
+		t.Fatal(err)
+	}
+	f.Cleanup()
+	out, err = f.Format()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `// workspace for local development
+go 1.18
+
+use (
+	./api
+	./operator // github.com/openshift/operator
+)
+
+replace k8s.io/api => k8s.io/api v0.20.0
+
+replace k8s.io/client-go => k8s.io/client-go v0.20.0
+`
+	if string(out) != expected {
+		t.Errorf("unexpected go.work:\n%s", out)
+	}
+
+	if _, err := ParseWorkFile("go.work", []byte("require k8s.io/api v0.19.0\n"), nil); err == nil {
+		t.Error("expected error for require directive in go.work")
+	}
+}
+
+func TestWorkspaceVersions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "workspace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeFile(t, filepath.Join(dir, "go.work"), testGoWork)
+	writeFile(t, filepath.Join(dir, "api", "go.mod"), `module github.com/openshift/api
+
+require (
+	k8s.io/api v0.18.0
+	k8s.io/apimachinery v0.18.0
+)
+`)
+	writeFile(t, filepath.Join(dir, "operator", "go.mod"), `module github.com/openshift/operator
+
+require (
+	github.com/openshift/api v0.0.0-20191016115129-c07a134afb42
+	k8s.io/apimachinery v0.19.0
+)
+
+replace k8s.io/apimachinery => k8s.io/apimachinery v0.19.2
+`)
+
+	work, modules, err := ReadWorkspace(filepath.Join(dir, "go.work"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(modules) != 2 || modules[0].Path != "github.com/openshift/api" || modules[1].Dir != "./operator" {
+		t.Fatalf("unexpected workspace modules: %#v", modules)
+	}
+	versions := WorkspaceVersions(work, modules)
+	if _, ok := versions["github.com/openshift/api"]; ok {
+		t.Error("workspace module must not be listed as dependency")
+	}
+	if m := versions["k8s.io/api"]; m.Version != "v0.18.0" || m.ReplaceVersion != "v0.19.0" {
+		t.Errorf("unexpected k8s.io/api: %#v", m)
+	}
+	if m := versions["k8s.io/apimachinery"]; m.Version != "v0.19.0" || m.ReplaceVersion != "v0.19.2" {
+		t.Errorf("unexpected k8s.io/apimachinery: %#v", m)
+	}
+}
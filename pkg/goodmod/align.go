@@ -0,0 +1,97 @@
+package goodmod
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mfojtik/goodmod/pkg/golang"
+	"github.com/mfojtik/goodmod/pkg/log"
+	"github.com/mfojtik/goodmod/pkg/resolve"
+	"github.com/mfojtik/goodmod/pkg/resolve/gomod"
+	"github.com/mfojtik/goodmod/pkg/resolve/types"
+)
+
+// fetchGoMod fetch the go.mod file of the module at given commit and return the modules it requires.
+func (opts *Options) fetchGoMod(ctx context.Context, modulePath string, c *types.Commit) (map[string]golang.ModuleVersion, error) {
+	fetchers := []resolve.GoModFetcher{
+		gomod.NewGithubGoModFetcher(opts.githubHosts()),
+		gomod.NewProxyGoModFetcher(),
+		gomod.NewGitGoModFetcher(opts.GitAuth),
+	}
+	// go.mod at the commit never change, so the local clones are used whenever they are configured
+	switch {
+	case opts.Offline:
+		fetchers = []resolve.GoModFetcher{gomod.NewLocalGoModFetcher(opts.localRepos())}
+	case len(opts.LocalRepos) > 0:
+		fetchers = append([]resolve.GoModFetcher{gomod.NewLocalGoModFetcher(opts.LocalRepos)}, fetchers...)
+	}
+	log.Infof("Fetching go.mod for module path %q at %q ...", modulePath, c.String())
+	for _, f := range fetchers {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		fetcherCtx, cancel := opts.resolverContext(ctx, f)
+		content, err := f.Fetch(fetcherCtx, modulePath, c)
+		cancel()
+		if err != nil {
+			log.WithModule(modulePath).Errorf("failed to fetch go.mod using %T: %v", f, err)
+			continue
+		}
+		return golang.UpstreamVersions(modulePath+"@"+c.String()+"/go.mod", content)
+	}
+	return nil, fmt.Errorf("unable to fetch go.mod for %s@%s", modulePath, c.String())
+}
+
+// completeAlign resolve the anchor module and set versions of all other modules to versions required by the anchor
+// module go.mod file.
+func (opts *Options) completeAlign(ctx context.Context) error {
+	anchorPath := opts.AlignWith
+	for _, r := range opts.replaces {
+		if r.oldPath == opts.AlignWith {
+			anchorPath = r.newPath
+		}
+	}
+	anchorCommit := opts.resolveModule(ctx, anchorPath)
+	if anchorCommit == nil {
+		return fmt.Errorf("unable to resolve anchor module %q", anchorPath)
+	}
+	upstream, err := opts.fetchGoMod(ctx, anchorPath, anchorCommit)
+	if err != nil {
+		return err
+	}
+	for i, r := range opts.replaces {
+		if r.oldPath == opts.AlignWith {
+			opts.replaces[i].newPathVersion = anchorCommit.String()
+			continue
+		}
+		version, err := alignedVersion(r, upstream)
+		if err != nil {
+			// the module keeps its version, it is reported as failed instead
+			opts.replaces[i].failure = fmt.Sprintf("conflict with %s@%s: %v", anchorPath, anchorCommit.String(), err)
+			if opts.FailFast {
+				return fmt.Errorf("failed to align %s: %s", r.oldPath, opts.replaces[i].failure)
+			}
+			continue
+		}
+		log.Infof("Module path %q aligned to %q required by %s", r.oldPath, version, anchorPath)
+		opts.replaces[i].newPathVersion = version
+	}
+	return nil
+}
+
+// alignedVersion return the version the upstream go.mod use for the module. Upstream replacing the module with different
+// path than we use is reported as conflict.
+func alignedVersion(r moduleReplace, upstream map[string]golang.ModuleVersion) (string, error) {
+	m, ok := upstream[r.oldPath]
+	if !ok {
+		return "", fmt.Errorf("module is not required")
+	}
+	path, version := m.Target()
+	if golang.IsDirectoryPath(path) {
+		return "", fmt.Errorf("module is replaced by local directory %q", path)
+	}
+	if path != r.newPath {
+		return "", fmt.Errorf("module is replaced by %s@%s, but %s is used", path, version, r.newPath)
+	}
+	return version, nil
+}
@@ -0,0 +1,91 @@
+package goodmod
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompleteAlign(t *testing.T) {
+	dir, err := ioutil.TempDir("", "align")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer os.Setenv("XDG_CACHE_HOME", os.Getenv("XDG_CACHE_HOME"))
+	os.Setenv("XDG_CACHE_HOME", dir)
+
+	src := filepath.Join(dir, "src")
+	repository := filepath.Join(src, "github.com", "openshift", "library-go")
+	if err := os.MkdirAll(repository, 0755); err != nil {
+		t.Fatal(err)
+	}
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repository
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Skipf("git %s: %v: %s", strings.Join(args, " "), err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "-q", "-b", "master")
+	upstream := `module github.com/openshift/library-go
+
+require (
+	github.com/openshift/api v0.0.0-20191105131421-3d4e2ac1b2ad
+	github.com/openshift/client-go v0.0.0-20191105131421-3d4e2ac1b2ad
+)
+
+replace github.com/openshift/client-go => github.com/fork/client-go v0.0.0-20191105131421-3d4e2ac1b2ad
+`
+	if err := ioutil.WriteFile(filepath.Join(repository, "go.mod"), []byte(upstream), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "go.mod")
+	git("commit", "-q", "-m", "initial")
+	sha := git("rev-parse", "HEAD")
+
+	opts := &Options{
+		GoModPath:   writeGoMod(t, dir, "github.com/openshift/library-go", "github.com/openshift/api", "github.com/openshift/client-go", "github.com/openshift/build-machinery-go"),
+		Target:      TargetGoMod,
+		Paths:       []string{"github.com/openshift/*"},
+		AlignWith:   "github.com/openshift/library-go",
+		Branch:      "master",
+		Concurrency: 1,
+		Offline:     true,
+		LocalRepos:  []string{src},
+		// requests to GitHub would fail the test
+		GithubClient: &http.Client{Transport: failingTransport{}},
+	}
+	if err := opts.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := opts.Complete(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]struct{ version, failure string }{
+		"github.com/openshift/library-go":         {version: sha[:12]},
+		"github.com/openshift/api":                {version: "v0.0.0-20191105131421-3d4e2ac1b2ad"},
+		"github.com/openshift/client-go":          {failure: "module is replaced by github.com/fork/client-go@v0.0.0-20191105131421-3d4e2ac1b2ad, but github.com/openshift/client-go is used"},
+		"github.com/openshift/build-machinery-go": {failure: "module is not required"},
+	}
+	for _, c := range opts.changes() {
+		e := expected[c.OldPath]
+		switch {
+		case len(e.failure) > 0 && (len(c.NewVersion) > 0 || !strings.HasPrefix(c.Failure, "conflict with github.com/openshift/library-go@") || !strings.HasSuffix(c.Failure, e.failure)):
+			t.Errorf("%s: expected failure %q, got %#v", c.OldPath, e.failure, c)
+		case len(e.version) > 0 && (!strings.HasSuffix(c.NewVersion, e.version) || len(c.Failure) > 0):
+			t.Errorf("%s: expected version %s, got %#v", c.OldPath, e.version, c)
+		}
+	}
+	if failed := (&Plan{Changes: opts.changes()}).Failed(); len(failed) != 2 {
+		t.Errorf("expected 2 modules reported as failed, got %#v", failed)
+	}
+}
@@ -0,0 +1,30 @@
+package goodmod
+
+import (
+	"context"
+	"fmt"
+)
+
+// Applier write the planned changes of resolved modules to go.mod (using 'go mod edit') or to go.work file. Modules
+// that failed to resolve are left unchanged.
+type Applier struct {
+	// UpdateGoSum add hashes of the new versions to go.sum and remove hashes of versions no longer used
+	UpdateGoSum bool
+	// Vendor refresh vendored packages of changed modules and vendor/modules.txt without running 'go mod vendor'
+	Vendor bool
+}
+
+// Apply write the changes of all rules of the plan.
+func (a *Applier) Apply(ctx context.Context, plan *Plan) error {
+	for _, o := range plan.rules {
+		if o.isWorkspace() && a.Vendor {
+			return fmt.Errorf("vendor cannot be combined with %q target", TargetGoWork)
+		}
+	}
+	for _, o := range plan.rules {
+		if err := o.apply(ctx, a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package goodmod
+
+import (
+	"context"
+
+	"github.com/mfojtik/goodmod/pkg/log"
+	"github.com/mfojtik/goodmod/pkg/resolve"
+	"github.com/mfojtik/goodmod/pkg/resolve/graphql"
+	"github.com/mfojtik/goodmod/pkg/resolve/types"
+)
+
+// batchRef return the ref resolveModule would resolve for the module path, the commit take priority over tag and
+// branch.
+func (opts *Options) batchRef(modulePath string) (graphql.Ref, bool) {
+	switch {
+	case len(opts.Commit) > 0:
+		return graphql.Ref{ModulePath: modulePath, Kind: resolve.CommitRef, Name: opts.Commit}, true
+	case len(opts.Tag) > 0:
+		return graphql.Ref{ModulePath: modulePath, Kind: resolve.TagRef, Name: opts.Tag}, true
+	case len(opts.Branch) > 0:
+		return graphql.Ref{ModulePath: modulePath, Kind: resolve.BranchRef, Name: opts.Branch}, true
+	default:
+		return graphql.Ref{}, false
+	}
+}
+
+// resolveBatch resolve all GitHub hosted repositories of the groups using the batch resolver in one or a few requests.
+// Modules that failed to resolve are left for the other resolvers.
+func (opts *Options) resolveBatch(ctx context.Context, groups []replaceGroup) error {
+	opts.batched = map[string]*types.Commit{}
+	if opts.BatchResolver == nil || opts.Chain != nil || opts.chainNames()[0] != resolve.GithubKind {
+		return nil
+	}
+	refs := []graphql.Ref{}
+	for _, g := range groups {
+		if !resolve.IsGithubModule(g.modulePath) {
+			continue
+		}
+		if ref, ok := opts.batchRef(g.modulePath); ok {
+			refs = append(refs, ref)
+		}
+	}
+	if len(refs) == 0 {
+		return nil
+	}
+
+	log.Debugf("Resolving %d modules using GitHub GraphQL API ...", len(refs))
+	resolverCtx, cancel := opts.resolverContext(ctx, opts.BatchResolver)
+	results, err := opts.BatchResolver.ResolveAll(resolverCtx, refs)
+	cancel()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil {
+		log.Debugf("Failed to resolve modules using GitHub GraphQL API: %v", err)
+	}
+	for ref, result := range results {
+		if result.Err != nil {
+			log.WithModule(ref.ModulePath).Debugf("failed to resolve %s using %T: %v", ref.Kind, opts.BatchResolver, result.Err)
+			opts.recordResolverError(ref.ModulePath, opts.BatchResolver, result.Err)
+			continue
+		}
+		opts.recordResolved(ref.ModulePath, opts.BatchResolver, result.Commit)
+		log.Debugf("Module path %q resolved to %q ...", ref.ModulePath, result.Commit.String())
+		opts.batched[ref.ModulePath] = result.Commit
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package goodmod
+
+import (
+	"fmt"
+
+	"github.com/mfojtik/goodmod/pkg/config"
+	"github.com/mfojtik/goodmod/pkg/log"
+	"github.com/mfojtik/goodmod/pkg/resolve"
+)
+
+// configToOptions return options for every rule in config file, or only for the rule matching the single module path.
+// True is returned when the config file does not exist.
+func configToOptions(configPath string, singleRule string, originalOptions Options) ([]*Options, bool, error) {
+	c, err := config.ReadRules(configPath, originalOptions.GoModPath)
+	if err == config.NotFoundError {
+		return nil, true, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	log.Infof("Loaded %d go.mod rules", len(c.Rules))
+	gitAuth := originalOptions.GitAuth
+	if len(c.GitAuth) > 0 {
+		if gitAuth, err = resolve.NewGitAuth(c.GitAuth); err != nil {
+			return nil, false, err
+		}
+	}
+	githubHosts := originalOptions.GithubHosts
+	if githubHosts == nil && len(c.GithubHosts) > 0 {
+		if githubHosts, err = resolve.NewGithubHosts(originalOptions.GithubClient, c.GithubHosts, originalOptions.Retries); err != nil {
+			return nil, false, err
+		}
+	}
+	// the single module that is not a rule path is resolved using the first rule matching it (eg. 'goodmod unlink')
+	var fallback *config.Rule
+	if len(singleRule) > 0 && !hasRulePath(c.Rules, singleRule) {
+		fallback = config.RuleForPath(c.Rules, singleRule)
+	}
+	options := []*Options{}
+	for i, rule := range c.Rules {
+		if fallback == &c.Rules[i] {
+			rule.Paths, rule.Excludes = []string{singleRule}, nil
+		} else if len(singleRule) > 0 {
+			found := false
+			for _, p := range rule.Paths {
+				if singleRule == p {
+					found = true
+					rule.Paths = []string{p}
+					break
+				}
+			}
+			if !found {
+				continue
+			}
+		}
+		matchedRule := rule
+		// resolvers set by flag take priority over the rule and the rule over the config file
+		resolvers := originalOptions.Resolvers
+		if len(resolvers) == 0 {
+			resolvers = rule.Resolvers
+		}
+		if len(resolvers) == 0 {
+			resolvers = c.Resolvers
+		}
+		execResolver := originalOptions.ExecResolver
+		if len(execResolver) == 0 {
+			execResolver = c.ExecResolver
+		}
+		options = append(options, &Options{
+			rule:              &matchedRule,
+			Branch:            rule.BranchName,
+			Commit:            rule.Commit,
+			Tag:               rule.TagName,
+			AlignWith:         rule.AlignWith,
+			KubernetesVersion: rule.KubernetesVersion,
+			SyncFrom:          rule.SyncFrom,
+			Follow:            rule.Follow,
+			Rules:             c.Rules,
+			Paths:             rule.Paths,
+			Excludes:          rule.Excludes,
+			GoModPath:         originalOptions.GoModPath,
+			Target:            originalOptions.Target,
+			GoWorkPath:        originalOptions.GoWorkPath,
+			GithubClient:      originalOptions.GithubClient,
+			GithubHosts:       githubHosts,
+			BatchResolver:     originalOptions.BatchResolver,
+			Resolvers:         resolvers,
+			ExecResolver:      execResolver,
+			Chain:             originalOptions.Chain,
+			GitAuth:           gitAuth,
+			LocalRepos:        originalOptions.LocalRepos,
+			Offline:           originalOptions.Offline,
+			ResolverTimeouts:  c.ResolverTimeouts,
+			Concurrency:       originalOptions.Concurrency,
+			Retries:           originalOptions.Retries,
+			FailFast:          originalOptions.FailFast,
+		})
+	}
+	if len(singleRule) > 0 && len(options) == 0 {
+		return nil, false, fmt.Errorf("no rule matched %q", singleRule)
+	}
+	return options, false, nil
+}
+
+// configSettings return the options with the settings of the config file (GitHub hosts, git auth, resolvers and their
+// timeouts), the rules are not used. The options are returned unchanged when the config file does not exist.
+func configSettings(configPath string, opts Options) (Options, error) {
+	c, err := config.ReadConfig(configPath)
+	if err == config.NotFoundError {
+		return opts, nil
+	}
+	if err != nil {
+		return opts, err
+	}
+	if len(c.GitAuth) > 0 {
+		if opts.GitAuth, err = resolve.NewGitAuth(c.GitAuth); err != nil {
+			return opts, err
+		}
+	}
+	if opts.GithubHosts == nil && len(c.GithubHosts) > 0 {
+		if opts.GithubHosts, err = resolve.NewGithubHosts(opts.GithubClient, c.GithubHosts, opts.Retries); err != nil {
+			return opts, err
+		}
+	}
+	// resolvers set by flag take priority over the config file
+	if len(opts.Resolvers) == 0 {
+		opts.Resolvers = c.Resolvers
+	}
+	if len(opts.ExecResolver) == 0 {
+		opts.ExecResolver = c.ExecResolver
+	}
+	opts.ResolverTimeouts = c.ResolverTimeouts
+	return opts, nil
+}
+
+// hasRulePath return true when the path is listed in paths of any rule.
+func hasRulePath(rules []config.Rule, path string) bool {
+	for _, rule := range rules {
+		for _, p := range rule.Paths {
+			if p == path {
+				return true
+			}
+		}
+	}
+	return false
+}
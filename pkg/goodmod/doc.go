@@ -0,0 +1,18 @@
+// Package goodmod plan and apply changes of go.mod (or go.work) files using the goodmod rules, so the tooling can be
+// used without running the goodmod binary and parsing its output.
+//
+// The Planner resolve the modules matching the rules of the config file (or the rule set in Options) to the planned
+// changes and the Applier write them:
+//
+//	planner := &goodmod.Planner{Options: goodmod.Options{GoModPath: "go.mod", ConfigPath: "goodmod.yaml"}}
+//	plan, err := planner.Plan(ctx, "")
+//	if err != nil {
+//		return err
+//	}
+//	if len(plan.Failed()) > 0 {
+//		return fmt.Errorf("%d modules failed to resolve", len(plan.Failed()))
+//	}
+//	return (&goodmod.Applier{UpdateGoSum: true}).Apply(ctx, plan)
+//
+// The Reporter return the current and desired versions of all modules.
+package goodmod
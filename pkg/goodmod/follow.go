@@ -0,0 +1,144 @@
+package goodmod
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mfojtik/goodmod/pkg/config"
+	"github.com/mfojtik/goodmod/pkg/golang"
+	"github.com/mfojtik/goodmod/pkg/log"
+	"github.com/mfojtik/goodmod/pkg/resolve/types"
+)
+
+type followCycleError struct {
+	chain []string
+}
+
+func (e *followCycleError) Error() string {
+	return fmt.Sprintf("follow cycle detected: %s", strings.Join(e.chain, " -> "))
+}
+
+type followedModule struct {
+	path    string
+	commit  *types.Commit
+	modules map[string]golang.ModuleVersion
+}
+
+// versionCommit resolve the module version (pseudo-version or tag) to commit.
+func (opts *Options) versionCommit(ctx context.Context, modulePath, version string) *types.Commit {
+	if sha, ok := golang.PseudoVersionCommit(version); ok {
+		return opts.resolveByCommit(ctx, modulePath, sha)
+	}
+	return opts.resolveByTag(ctx, modulePath, strings.TrimSuffix(version, "+incompatible"))
+}
+
+// currentPin return the path and version go.mod currently use for the module.
+func (opts *Options) currentPin(modulePath string) (string, string) {
+	m, ok := opts.goModModules[modulePath]
+	if !ok {
+		return modulePath, ""
+	}
+	return m.Target()
+}
+
+// followedCommit resolve the followed module using its own rule or using the version go.mod currently use.
+// The chain include all modules that are being followed and it is used to detect cycles.
+func (opts *Options) followedCommit(ctx context.Context, modulePath string, chain []string) (string, *types.Commit, error) {
+	path, version := opts.currentPin(modulePath)
+	rule := config.RuleForPath(opts.Rules, modulePath)
+	switch {
+	case rule != nil && len(rule.Follow) > 0 && rule.Follow != modulePath:
+		followPath, followVersion, err := opts.followedVersion(ctx, modulePath, rule.Follow, chain)
+		if err != nil {
+			return "", nil, err
+		}
+		path, version = followPath, followVersion
+	case rule != nil && len(rule.Follow) == 0 && (len(rule.BranchName) > 0 || len(rule.TagName) > 0 || len(rule.Commit) > 0):
+		ruleOptions := &Options{Branch: rule.BranchName, Tag: rule.TagName, Commit: rule.Commit, GithubClient: opts.GithubClient, GithubHosts: opts.GithubHosts, ResolverTimeouts: opts.ResolverTimeouts, Resolvers: opts.Resolvers, ExecResolver: opts.ExecResolver, Chain: opts.Chain, GitAuth: opts.GitAuth, LocalRepos: opts.LocalRepos, Offline: opts.Offline}
+		if len(rule.Resolvers) > 0 {
+			ruleOptions.Resolvers = rule.Resolvers
+		}
+		if c := ruleOptions.resolveModule(ctx, path); c != nil {
+			return path, c, nil
+		}
+		return "", nil, fmt.Errorf("unable to resolve followed module %q using its rule", modulePath)
+	}
+	if len(version) == 0 {
+		return "", nil, fmt.Errorf("followed module %q is not required in %s and has no rule", modulePath, opts.GoModPath)
+	}
+	if golang.IsDirectoryPath(path) {
+		return "", nil, fmt.Errorf("followed module %q is replaced by local directory %q", modulePath, path)
+	}
+	c := opts.versionCommit(ctx, path, version)
+	if c == nil {
+		return "", nil, fmt.Errorf("unable to resolve followed module %q version %q", modulePath, version)
+	}
+	return path, c, nil
+}
+
+// followedVersion return the path and version the go.mod file of the followed module use for the module.
+func (opts *Options) followedVersion(ctx context.Context, modulePath, followPath string, chain []string) (string, string, error) {
+	for _, p := range chain {
+		if p == followPath {
+			return "", "", &followCycleError{chain: append(chain, followPath)}
+		}
+	}
+	chain = append(chain, followPath)
+
+	followed, ok := opts.followed[followPath]
+	if !ok {
+		path, c, err := opts.followedCommit(ctx, followPath, chain)
+		if err != nil {
+			return "", "", err
+		}
+		modules, err := opts.fetchGoMod(ctx, path, c)
+		if err != nil {
+			return "", "", err
+		}
+		followed = &followedModule{path: path, commit: c, modules: modules}
+		opts.followed[followPath] = followed
+	}
+
+	m, ok := followed.modules[modulePath]
+	if !ok {
+		return "", "", fmt.Errorf("module %q is not required by followed module %s@%s", modulePath, followed.path, followed.commit.String())
+	}
+	path, version := m.Target()
+	if golang.IsDirectoryPath(path) {
+		return "", "", fmt.Errorf("module %q is replaced by local directory %q in followed module %s@%s", modulePath, path, followed.path, followed.commit.String())
+	}
+	return path, version, nil
+}
+
+// completeFollow set all modules to the versions the followed module go.mod use.
+func (opts *Options) completeFollow(ctx context.Context) error {
+	opts.followed = map[string]*followedModule{}
+	for i, r := range opts.replaces {
+		if r.oldPath == opts.Follow {
+			continue
+		}
+		path, version, err := opts.followedVersion(ctx, r.oldPath, opts.Follow, []string{r.oldPath})
+		if err != nil {
+			if _, isCycle := err.(*followCycleError); isCycle {
+				return err
+			}
+			opts.replaces[i].failure = err.Error()
+			if opts.FailFast {
+				return fmt.Errorf("failed to resolve %s: %v", r.oldPath, err)
+			}
+			continue
+		}
+		if path != r.newPath {
+			// the module keeps its version, it is reported as failed instead
+			opts.replaces[i].failure = fmt.Sprintf("conflict: followed module %q use %s@%s, but %s is used", opts.Follow, path, version, r.newPath)
+			if opts.FailFast {
+				return fmt.Errorf("failed to follow %s: %s", r.oldPath, opts.replaces[i].failure)
+			}
+			continue
+		}
+		log.Infof("Module path %q set to %q used by followed module %q", r.oldPath, version, opts.Follow)
+		opts.replaces[i].newPathVersion = version
+	}
+	return nil
+}
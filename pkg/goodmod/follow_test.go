@@ -0,0 +1,124 @@
+package goodmod
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mfojtik/goodmod/pkg/config"
+)
+
+func TestFollowCycle(t *testing.T) {
+	opts := &Options{
+		Follow: "github.com/openshift/library-go",
+		Rules: []config.Rule{
+			{Paths: []string{"github.com/openshift/api"}, Follow: "github.com/openshift/library-go"},
+			{Paths: []string{"github.com/openshift/library-go"}, Follow: "github.com/openshift/client-go"},
+			{Paths: []string{"github.com/openshift/client-go"}, Follow: "github.com/openshift/api"},
+		},
+		replaces: []moduleReplace{
+			{oldPath: "github.com/openshift/api", newPath: "github.com/openshift/api"},
+		},
+	}
+	err := opts.completeFollow(context.TODO())
+	if err == nil {
+		t.Fatal("expected cycle error")
+	}
+	expected := "follow cycle detected: github.com/openshift/api -> github.com/openshift/library-go -> github.com/openshift/client-go -> github.com/openshift/api"
+	if err.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, err.Error())
+	}
+}
+
+func TestCompleteFollow(t *testing.T) {
+	dir, err := ioutil.TempDir("", "follow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer os.Setenv("XDG_CACHE_HOME", os.Getenv("XDG_CACHE_HOME"))
+	os.Setenv("XDG_CACHE_HOME", dir)
+
+	src := filepath.Join(dir, "src")
+	repository := filepath.Join(src, "github.com", "openshift", "library-go")
+	if err := os.MkdirAll(repository, 0755); err != nil {
+		t.Fatal(err)
+	}
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repository
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Skipf("git %s: %v: %s", strings.Join(args, " "), err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "-q", "-b", "master")
+	upstream := `module github.com/openshift/library-go
+
+require (
+	github.com/openshift/api v0.0.0-20191105131421-3d4e2ac1b2ad
+	github.com/openshift/client-go v0.0.0-20191105131421-3d4e2ac1b2ad
+)
+
+replace github.com/openshift/client-go => github.com/fork/client-go v0.0.0-20191105131421-3d4e2ac1b2ad
+`
+	if err := ioutil.WriteFile(filepath.Join(repository, "go.mod"), []byte(upstream), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "go.mod")
+	git("commit", "-q", "-m", "initial")
+	sha := git("rev-parse", "HEAD")
+
+	newOptions := func(failFast bool) *Options {
+		opts := &Options{
+			GoModPath: writeGoMod(t, dir, "github.com/openshift/library-go", "github.com/openshift/api", "github.com/openshift/client-go", "github.com/openshift/build-machinery-go"),
+			Target:    TargetGoMod,
+			Paths:     []string{"github.com/openshift/*"},
+			Follow:    "github.com/openshift/library-go",
+			// the followed module is resolved using its rule
+			Rules:       []config.Rule{{Paths: []string{"github.com/openshift/library-go"}, BranchName: "master"}},
+			FailFast:    failFast,
+			Concurrency: 1,
+			Offline:     true,
+			LocalRepos:  []string{src},
+			// requests to GitHub would fail the test
+			GithubClient: &http.Client{Transport: failingTransport{}},
+		}
+		if err := opts.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		return opts
+	}
+
+	opts := newOptions(false)
+	if err := opts.Complete(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]struct{ version, failure string }{
+		"github.com/openshift/library-go":         {},
+		"github.com/openshift/api":                {version: "v0.0.0-20191105131421-3d4e2ac1b2ad"},
+		"github.com/openshift/client-go":          {failure: `conflict: followed module "github.com/openshift/library-go" use github.com/fork/client-go@v0.0.0-20191105131421-3d4e2ac1b2ad, but github.com/openshift/client-go is used`},
+		"github.com/openshift/build-machinery-go": {failure: `module "github.com/openshift/build-machinery-go" is not required by followed module github.com/openshift/library-go@`},
+	}
+	for _, c := range opts.changes() {
+		e := expected[c.OldPath]
+		if c.NewVersion != e.version || !strings.HasPrefix(c.Failure, e.failure) || (len(e.failure) == 0 && len(c.Failure) > 0) {
+			t.Errorf("%s: expected version %q and failure %q, got %#v", c.OldPath, e.version, e.failure, c)
+		}
+		if c.OldPath == "github.com/openshift/build-machinery-go" && !strings.Contains(c.Failure, sha[:12]) {
+			t.Errorf("expected failure to include followed commit %s, got %q", sha[:12], c.Failure)
+		}
+	}
+
+	opts = newOptions(true)
+	if err := opts.Complete(context.TODO()); err == nil || !strings.HasPrefix(err.Error(), "failed to follow github.com/openshift/client-go: conflict") {
+		t.Errorf("expected fail fast error, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package goodmod
+
+import (
+	"sort"
+
+	"github.com/mfojtik/goodmod/pkg/log"
+	"github.com/mfojtik/goodmod/pkg/resolve"
+)
+
+// replaceGroup are the replaces of modules located in the same repository, they resolve to the same commit of the ref.
+type replaceGroup struct {
+	// modulePath is the module path resolvers are called with, the shortest path is used as it is the closest to the
+	// repository root
+	modulePath string
+	indexes    []int
+}
+
+// groupReplaces group the replaces by the repository and the ref, so every repository is resolved only once. The groups
+// are ordered by the first replace in the group.
+func (opts *Options) groupReplaces() []replaceGroup {
+	ref, _ := opts.batchRef("")
+	type groupKey struct {
+		repository string
+		ref        string
+	}
+	keys := map[groupKey]int{}
+	groups := []replaceGroup{}
+	for i, replace := range opts.replaces {
+		key := groupKey{repository: resolve.RepositoryRoot(replace.newPath), ref: string(ref.Kind) + ":" + ref.Name}
+		g, ok := keys[key]
+		if !ok {
+			g = len(groups)
+			keys[key] = g
+			groups = append(groups, replaceGroup{modulePath: replace.newPath})
+		}
+		if len(replace.newPath) < len(groups[g].modulePath) {
+			groups[g].modulePath = replace.newPath
+		}
+		groups[g].indexes = append(groups[g].indexes, i)
+	}
+	return groups
+}
+
+// modulePaths return the distinct module paths of the replaces in the group.
+func (g replaceGroup) modulePaths(replaces []moduleReplace) []string {
+	seen := map[string]bool{}
+	paths := []string{}
+	for _, i := range g.indexes {
+		if path := replaces[i].newPath; !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// logGroupStats report how many lookups were saved by resolving every repository once.
+func (opts *Options) logGroupStats(groups []replaceGroup) {
+	modules := 0
+	for _, g := range groups {
+		modules += len(g.modulePaths(opts.replaces))
+	}
+	if saved := modules - len(groups); saved > 0 {
+		log.Infof("Resolved %d modules from %d repositories, %d lookups saved", modules, len(groups), saved)
+	}
+}
@@ -0,0 +1,79 @@
+package goodmod
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mfojtik/goodmod/pkg/golang"
+	"github.com/mfojtik/goodmod/pkg/log"
+)
+
+const (
+	kubernetesModulePath = "k8s.io/kubernetes"
+
+	// kubernetesStagingDir is where kubernetes/kubernetes keep source of modules published to k8s.io/* repositories
+	kubernetesStagingDir = "./staging/src/"
+)
+
+var kubernetesVersionRE = regexp.MustCompile(`^v1\.([0-9]+)\.([0-9]+)(-.+)?$`)
+
+// kubernetesStagingVersion return version of staging modules (eg. k8s.io/api) published for kubernetes version.
+// Kubernetes v1.19.2 publish staging modules as v0.19.2.
+func kubernetesStagingVersion(kubernetesVersion string) (string, error) {
+	parts := kubernetesVersionRE.FindStringSubmatch(kubernetesVersion)
+	if parts == nil {
+		return "", fmt.Errorf("invalid kubernetes version %q, must be of the form v1.19.2", kubernetesVersion)
+	}
+	if minor, _ := strconv.Atoi(parts[1]); minor < 17 {
+		return "", fmt.Errorf("kubernetes version %q is not supported, staging modules are versioned since v1.17.0", kubernetesVersion)
+	}
+	return fmt.Sprintf("v0.%s.%s%s", parts[1], parts[2], parts[3]), nil
+}
+
+// kubernetesModules return the modules required by kubernetes/kubernetes go.mod with staging modules (replaced by local
+// staging directory) mapped to published k8s.io/* modules.
+func kubernetesModules(upstream map[string]golang.ModuleVersion, stagingVersion string) map[string]golang.ModuleVersion {
+	result := map[string]golang.ModuleVersion{}
+	for path, m := range upstream {
+		if strings.HasPrefix(m.ReplacePath, kubernetesStagingDir) {
+			result[path] = golang.ModuleVersion{Path: path, Version: stagingVersion}
+			continue
+		}
+		result[path] = m
+	}
+	return result
+}
+
+// completeKubernetes set versions of all modules to versions kubernetes/kubernetes go.mod use at the kubernetes version tag.
+func (opts *Options) completeKubernetes(ctx context.Context) error {
+	stagingVersion, err := kubernetesStagingVersion(opts.KubernetesVersion)
+	if err != nil {
+		return err
+	}
+	kubernetesCommit := opts.resolveByTag(ctx, kubernetesModulePath, opts.KubernetesVersion)
+	if kubernetesCommit == nil {
+		return fmt.Errorf("unable to resolve kubernetes version %q", opts.KubernetesVersion)
+	}
+	upstream, err := opts.fetchGoMod(ctx, kubernetesModulePath, kubernetesCommit)
+	if err != nil {
+		return err
+	}
+	upstream = kubernetesModules(upstream, stagingVersion)
+	for i, r := range opts.replaces {
+		version, err := alignedVersion(r, upstream)
+		if err != nil {
+			// the module keeps its version, it is reported as failed instead
+			opts.replaces[i].failure = fmt.Sprintf("conflict with kubernetes %s: %v", opts.KubernetesVersion, err)
+			if opts.FailFast {
+				return fmt.Errorf("failed to align %s with kubernetes: %s", r.oldPath, opts.replaces[i].failure)
+			}
+			continue
+		}
+		log.Infof("Module path %q set to %q used by kubernetes %s", r.oldPath, version, opts.KubernetesVersion)
+		opts.replaces[i].newPathVersion = version
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package goodmod
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestKubernetesStagingVersion(t *testing.T) {
+	tests := []struct {
+		version        string
+		stagingVersion string
+		expectError    bool
+	}{
+		{version: "v1.19.2", stagingVersion: "v0.19.2"},
+		{version: "v1.20.0-rc.0", stagingVersion: "v0.20.0-rc.0"},
+		{version: "v1.16.2", expectError: true},
+		{version: "kubernetes-1.16.2", expectError: true},
+	}
+	for _, test := range tests {
+		t.Run(test.version, func(t *testing.T) {
+			stagingVersion, err := kubernetesStagingVersion(test.version)
+			if (err != nil) != test.expectError {
+				t.Fatalf("expected error=%t, got %v", test.expectError, err)
+			}
+			if stagingVersion != test.stagingVersion {
+				t.Errorf("expected %q, got %q", test.stagingVersion, stagingVersion)
+			}
+		})
+	}
+}
+
+func TestCompleteKubernetes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubernetes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer os.Setenv("XDG_CACHE_HOME", os.Getenv("XDG_CACHE_HOME"))
+	os.Setenv("XDG_CACHE_HOME", dir)
+
+	src := filepath.Join(dir, "src")
+	repository := filepath.Join(src, "k8s.io", "kubernetes")
+	if err := os.MkdirAll(repository, 0755); err != nil {
+		t.Fatal(err)
+	}
+	git := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repository
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Skipf("git %s: %v: %s", strings.Join(args, " "), err, out)
+		}
+	}
+	git("init", "-q", "-b", "master")
+	upstream := `module k8s.io/kubernetes
+
+require (
+	k8s.io/api v0.0.0
+	k8s.io/utils v0.0.0-20200729134348-d5654de09c73
+)
+
+replace k8s.io/api => ./staging/src/k8s.io/api
+`
+	if err := ioutil.WriteFile(filepath.Join(repository, "go.mod"), []byte(upstream), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "go.mod")
+	git("commit", "-q", "-m", "initial")
+	git("tag", "v1.19.2")
+
+	newOptions := func(failFast bool) *Options {
+		opts := &Options{
+			GoModPath:         writeGoMod(t, dir, "k8s.io/api", "k8s.io/utils", "k8s.io/klog"),
+			Target:            TargetGoMod,
+			KubernetesVersion: "v1.19.2",
+			FailFast:          failFast,
+			Concurrency:       1,
+			Offline:           true,
+			LocalRepos:        []string{src},
+			// requests to GitHub would fail the test
+			GithubClient: &http.Client{Transport: failingTransport{}},
+		}
+		if err := opts.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		return opts
+	}
+
+	opts := newOptions(false)
+	if err := opts.Complete(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]struct{ version, failure string }{
+		"k8s.io/api":   {version: "v0.19.2"},
+		"k8s.io/utils": {version: "v0.0.0-20200729134348-d5654de09c73"},
+		"k8s.io/klog":  {failure: "conflict with kubernetes v1.19.2: module is not required"},
+	}
+	for _, c := range opts.changes() {
+		e := expected[c.OldPath]
+		if c.NewVersion != e.version || c.Failure != e.failure {
+			t.Errorf("%s: expected version %q and failure %q, got %#v", c.OldPath, e.version, e.failure, c)
+		}
+	}
+
+	opts = newOptions(true)
+	if err := opts.Complete(context.TODO()); err == nil || !strings.Contains(err.Error(), "failed to align k8s.io/klog with kubernetes") {
+		t.Errorf("expected fail fast error, got %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package goodmod
+
+import (
+	"context"
+	"os"
+
+	"github.com/mfojtik/goodmod/pkg/resolve"
+	"github.com/mfojtik/goodmod/pkg/resolve/graphql"
+)
+
+// Planner resolve the modules matching the rules of the config file to the planned changes. When the config file does
+// not exist, the rule set in Options (eg. Paths and Branch) is used.
+type Planner struct {
+	Options
+
+	// IgnoreConfig plan only the rule set in Options, the config file and the go.mod annotations are not read
+	IgnoreConfig bool
+	// IgnoreRules plan only the rule set in Options, the settings of the config file (eg. GitHub hosts, git auth and
+	// resolvers) are used but its rules and the go.mod annotations are not
+	IgnoreRules bool
+}
+
+// Plan is the result of planning, the changes are written by Applier.
+type Plan struct {
+	// Changes are the planned changes of all modules matching the rules, in the order of rules
+	Changes []Change
+
+	// rules are the resolved options of every rule
+	rules []*Options
+}
+
+// Plan resolve the modules of all rules. When the module path is set, only the rule matching it is used and the
+// module must match a rule. Modules failing to resolve are reported in the plan changes (see Plan.Failed), unless
+// FailFast is set.
+// When GithubClient is not set, the client using GITHUB_TOKEN is used. When the token is set, the GitHub GraphQL API
+// resolve the modules at once.
+func (p *Planner) Plan(ctx context.Context, modulePath string) (*Plan, error) {
+	opts := p.Options
+	if opts.GithubClient == nil {
+		opts.GithubClient = resolve.NewGithubClient(githubToken(), opts.Retries)
+	}
+	if opts.BatchResolver == nil && len(githubToken()) > 0 {
+		opts.BatchResolver = graphql.NewGithubBatchResolver(opts.GithubClient, "")
+	}
+	if opts.GitAuth == nil {
+		auth, err := resolve.NewGitAuth(nil)
+		if err != nil {
+			return nil, err
+		}
+		opts.GitAuth = auth
+	}
+	if p.IgnoreRules && !p.IgnoreConfig {
+		var err error
+		if opts, err = configSettings(opts.ConfigPath, opts); err != nil {
+			return nil, err
+		}
+	}
+	options, noConfig := []*Options{}, true
+	if !p.IgnoreConfig && !p.IgnoreRules {
+		var err error
+		if options, noConfig, err = configToOptions(opts.ConfigPath, modulePath, opts); err != nil {
+			return nil, err
+		}
+	}
+	if noConfig {
+		options = []*Options{&opts}
+	}
+	for _, o := range options {
+		if err := o.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	plan := &Plan{Changes: []Change{}, rules: options}
+	for _, o := range options {
+		if err := o.Complete(ctx); err != nil {
+			return nil, err
+		}
+		plan.Changes = append(plan.Changes, o.changes()...)
+	}
+	return plan, nil
+}
+
+// Failed return the changes of modules that failed to resolve.
+func (p *Plan) Failed() []Change {
+	failed := []Change{}
+	for _, c := range p.Changes {
+		if len(c.Failure) > 0 {
+			failed = append(failed, c)
+		}
+	}
+	return failed
+}
+
+// Change return the planned change of the module, false is returned when the module did not match any rule.
+func (p *Plan) Change(modulePath string) (Change, bool) {
+	for _, c := range p.Changes {
+		if c.OldPath == modulePath {
+			return c, true
+		}
+	}
+	return Change{}, false
+}
+
+// Commands return the go commands that apply the changes of resolved modules (eg. 'go mod edit -replace ...').
+func (p *Plan) Commands() []string {
+	commands := []string{}
+	for _, o := range p.rules {
+		commands = append(commands, o.commands()...)
+	}
+	return commands
+}
+
+// githubToken return the token used by the default GitHub client.
+func githubToken() string {
+	return os.Getenv("GITHUB_TOKEN")
+}
@@ -0,0 +1,80 @@
+package goodmod
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mfojtik/goodmod/pkg/resolve"
+	"github.com/mfojtik/goodmod/pkg/resolve/types"
+)
+
+// fakeResolver resolve the branch of known modules to the commit.
+type fakeResolver struct {
+	commits map[string]*types.Commit
+}
+
+func (r *fakeResolver) Resolve(_ context.Context, modulePath string, name string) (*types.Commit, error) {
+	if c, ok := r.commits[modulePath+"@"+name]; ok {
+		return c, nil
+	}
+	return nil, fmt.Errorf("unknown branch %s", name)
+}
+
+func TestPlanApply(t *testing.T) {
+	dir, err := ioutil.TempDir("", "planner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	goModPath := writeGoMod(t, dir, "github.com/openshift/api", "github.com/openshift/client-go")
+
+	c := &types.Commit{SHA: "c07a134afb42e6ef4ecbff1e43b7eb5af1d6a4a8", Timestamp: time.Date(2019, 10, 16, 11, 51, 29, 0, time.UTC)}
+	resolver := &fakeResolver{commits: map[string]*types.Commit{"github.com/openshift/api@master": c}}
+	planner := &Planner{
+		Options: Options{
+			GoModPath: goModPath,
+			Paths:     []string{"github.com/openshift/*"},
+			Branch:    "master",
+			Chain: func(kind resolve.RefKind) ([]resolve.ModulerResolver, error) {
+				return []resolve.ModulerResolver{resolver}, nil
+			},
+		},
+		IgnoreConfig: true,
+	}
+	plan, err := planner.Plan(context.TODO(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	change, ok := plan.Change("github.com/openshift/api")
+	if !ok || !change.Changed || change.NewVersion != c.String() || change.Resolver != "goodmod.fakeResolver" {
+		t.Errorf("unexpected change: %#v", change)
+	}
+	failed := plan.Failed()
+	if len(failed) != 1 || failed[0].OldPath != "github.com/openshift/client-go" || !strings.Contains(failed[0].Failure, "unknown branch master") {
+		t.Errorf("expected client-go failed, got %#v", failed)
+	}
+	expected := `go mod edit -replace github.com/openshift/api=github.com/openshift/api@"` + c.String() + `"`
+	if commands := plan.Commands(); len(commands) != 1 || commands[0] != expected {
+		t.Errorf("unexpected commands: %#v", commands)
+	}
+
+	if err := (&Applier{}).Apply(context.TODO(), plan); err != nil {
+		t.Fatal(err)
+	}
+	goMod, err := ioutil.ReadFile(goModPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(goMod), "replace github.com/openshift/api => github.com/openshift/api "+c.String()) {
+		t.Errorf("expected replace applied, got:\n%s", goMod)
+	}
+	if err := (&Applier{Vendor: true}).Apply(context.TODO(), &Plan{rules: []*Options{{Target: TargetGoWork}}}); err == nil {
+		t.Errorf("expected vendor with go.work target to fail")
+	}
+}
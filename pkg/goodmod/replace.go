@@ -0,0 +1,505 @@
+package goodmod
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mfojtik/goodmod/pkg/config"
+	"github.com/mfojtik/goodmod/pkg/golang"
+	"github.com/mfojtik/goodmod/pkg/log"
+	"github.com/mfojtik/goodmod/pkg/resolve"
+	"github.com/mfojtik/goodmod/pkg/resolve/chain"
+	"github.com/mfojtik/goodmod/pkg/resolve/graphql"
+	"github.com/mfojtik/goodmod/pkg/resolve/types"
+)
+
+type moduleReplace struct {
+	oldPath        string
+	oldPathVersion string
+	newPath        string
+	newPathVersion string
+
+	// oldTargetPath is the module path go.mod use before the replace (oldPathVersion is its version)
+	oldTargetPath string
+
+	// required is set when the module is only required (not replaced) in go.mod
+	required bool
+	// setRequire is set when the new version should be written as require instead of replace
+	setRequire bool
+	// failure is the reason the module failed to resolve
+	failure string
+	// skipped is the reason the module keeps its version (eg. the sync source does not require it)
+	skipped string
+}
+
+// DefaultConcurrency is the number of modules resolved at once, it is kept low to avoid GitHub secondary rate limits.
+const DefaultConcurrency = 4
+
+// Options configure how modules are resolved, either by a single rule (eg. set by flags) or by all rules of the config
+// file (see Planner).
+type Options struct {
+	Branch string
+	Commit string
+	Tag    string
+
+	// KubernetesVersion set versions of modules to versions used by kubernetes/kubernetes at this version tag
+	KubernetesVersion string
+
+	Paths      []string
+	Excludes   []string
+	GoModPath  string
+	ConfigPath string
+	AlignWith  string
+
+	// Follow is a module which go.mod file dictate versions of matching modules
+	Follow string
+	// Rules are all rules from config file, used to resolve followed modules
+	Rules []config.Rule
+
+	// SyncFrom is a module, repository or local go.mod file path to copy versions of matching modules from
+	SyncFrom string
+
+	// Target is the file to write replace directives to, either go.mod or go.work
+	Target     string
+	GoWorkPath string
+
+	GithubClient *http.Client
+	// GithubHosts route GitHub API requests to github.com (using GithubClient) or to the GitHub Enterprise Server hosting
+	// the module
+	GithubHosts *resolve.GithubHosts
+	// BatchResolver resolve GitHub hosted modules using a single GraphQL query before the other resolvers are used, it
+	// is only set when GitHub token is available and only used when the chain starts with GitHub
+	BatchResolver *graphql.GithubBatchResolver
+
+	// Resolvers are the names of resolvers called in order to resolve branch, tag or commit (eg. 'cache', 'github'),
+	// ExecResolver is the command called by the 'exec' resolver
+	Resolvers    []string
+	ExecResolver string
+	// Chain return the resolvers called in order to resolve the ref kind, it is used instead of the chain built from
+	// Resolvers (eg. to resolve modules using resolvers implemented outside of goodmod)
+	Chain ChainFunc
+	// GitAuth select the authentication of git clones by module path
+	GitAuth *resolve.GitAuth
+	// LocalRepos are the directories the 'local' resolver search existing clones in (eg. GOPATH-style '~/go/src'),
+	// Offline refuse to use resolvers and fetchers that use the network
+	LocalRepos []string
+	Offline    bool
+
+	// ResolverTimeouts limit every call of a resolver kind (eg. 'github' or 'git')
+	ResolverTimeouts map[string]time.Duration
+
+	// Concurrency is the number of modules resolved at once, Retries is the number of times failed GitHub requests are
+	// retried
+	Concurrency int
+	Retries     int
+
+	// FailFast stop resolving at the first module that failed to resolve
+	FailFast bool
+
+	replaces []moduleReplace
+	// rule is the config file rule these options were created from
+	rule        *config.Rule
+	resolutions *resolutions
+
+	goModModules map[string]golang.ModuleVersion
+	followed     map[string]*followedModule
+	// batched are the commits resolved by the batch resolver
+	batched map[string]*types.Commit
+}
+
+func (opts *Options) hasReplacePath(path string) bool {
+	for _, p := range opts.replaces {
+		if p.oldPath == path {
+			return true
+		}
+	}
+	return false
+}
+
+func (opts *Options) matchPath(path string) bool {
+	// the anchor module is always included when aligning
+	if len(opts.AlignWith) > 0 && path == opts.AlignWith {
+		return true
+	}
+	return config.MatchPath(opts.Paths, opts.Excludes, path)
+}
+
+// parseModules will parse the existing go.mod file and filter out only modules matching the name prefixes specified with this command
+func (opts *Options) parseModules() error {
+	if opts.isWorkspace() {
+		return opts.parseWorkspaceModules()
+	}
+	modBytes, err := ioutil.ReadFile(opts.GoModPath)
+	if err != nil {
+		return err
+	}
+	s, err := golang.ParseModFile("go.mod", modBytes, nil)
+	if err != nil {
+		return err
+	}
+	if opts.goModModules, err = golang.RequiredVersions("go.mod", modBytes); err != nil {
+		return err
+	}
+	opts.replaces = []moduleReplace{}
+	linked := map[string]bool{}
+	for _, r := range s.Replace {
+		if !opts.matchPath(r.Old.Path) {
+			continue
+		}
+		if golang.IsDirectoryPath(r.New.Path) {
+			logLinked(r.Old.Path, r.New.Path)
+			linked[r.Old.Path] = true
+			continue
+		}
+		opts.replaces = append(opts.replaces, moduleReplace{newPath: r.New.Path, oldPath: r.Old.Path, oldPathVersion: r.New.Version, oldTargetPath: r.New.Path})
+	}
+	for _, r := range s.Require {
+		if !linked[r.Mod.Path] && !opts.hasReplacePath(r.Mod.Path) && opts.matchPath(r.Mod.Path) {
+			opts.replaces = append(opts.replaces, moduleReplace{newPath: r.Mod.Path, oldPath: r.Mod.Path, oldPathVersion: r.Mod.Version, oldTargetPath: r.Mod.Path, required: true})
+		}
+	}
+	return nil
+}
+
+// logLinked report the module replaced by local directory (eg. by 'goodmod link'), such modules are never resolved.
+func logLinked(modulePath, dir string) {
+	log.WithModule(modulePath).Infof("linked to local directory %q, use 'goodmod unlink' to restore", dir)
+}
+
+// resolverContext return context for a single call of resolver or fetcher, limited by the timeout set for its kind.
+func (opts *Options) resolverContext(ctx context.Context, r interface{}) (context.Context, context.CancelFunc) {
+	return resolve.WithTimeout(ctx, opts.ResolverTimeouts[resolve.Kind(r)])
+}
+
+// githubHosts return the GitHub hosts, only github.com is known when they are not set.
+func (opts *Options) githubHosts() *resolve.GithubHosts {
+	if opts.GithubHosts == nil {
+		return &resolve.GithubHosts{Client: opts.GithubClient}
+	}
+	return opts.GithubHosts
+}
+
+// chainNames return the names of resolvers in the chain, in the order they are called.
+func (opts *Options) chainNames() []string {
+	if len(opts.Resolvers) == 0 && opts.Offline {
+		return chain.OfflineResolvers
+	}
+	if len(opts.Resolvers) == 0 {
+		return chain.DefaultResolvers
+	}
+	return opts.Resolvers
+}
+
+// ChainFunc return the resolvers called in order to resolve the ref kind. Resolvers implementing resolve.CommitStore
+// store the commits resolved by the resolvers following them.
+type ChainFunc func(kind resolve.RefKind) ([]resolve.ModulerResolver, error)
+
+// resolvers return the resolvers of the chain for the ref kind, the chain set in options take priority over the names.
+func (opts *Options) resolvers(kind resolve.RefKind) ([]resolve.ModulerResolver, error) {
+	if opts.Chain != nil {
+		return opts.Chain(kind)
+	}
+	return chain.New(opts.chainNames(), kind, chain.Options{Github: opts.githubHosts(), GitAuth: opts.GitAuth, ExecCommand: opts.ExecResolver, LocalRepos: opts.LocalRepos})
+}
+
+// localRepos return the directories local clones are searched in.
+func (opts *Options) localRepos() []string {
+	if len(opts.LocalRepos) == 0 {
+		return resolve.DefaultLocalRepos()
+	}
+	return opts.LocalRepos
+}
+
+// resolveRef resolve the ref to commit using the resolvers chain. The commit is stored by the resolvers before the one
+// that resolved it (eg. cache).
+func (opts *Options) resolveRef(ctx context.Context, kind resolve.RefKind, modulePath string, name string) *types.Commit {
+	resolvers, err := opts.resolvers(kind)
+	if err != nil {
+		log.WithModule(modulePath).Errorf("%v", err)
+		return nil
+	}
+	log.Debugf("Resolving module path %q using %s %q ...", modulePath, kind, name)
+	for i, r := range resolvers {
+		if ctx.Err() != nil {
+			return nil
+		}
+		resolverCtx, cancel := opts.resolverContext(ctx, r)
+		c, err := r.Resolve(resolverCtx, modulePath, name)
+		cancel()
+		if err != nil {
+			log.WithModule(modulePath).Infof("failed to resolve %s using %T: %v", kind, r, err)
+			opts.recordResolverError(modulePath, r, err)
+			continue
+		}
+		for _, previous := range resolvers[:i] {
+			if store, ok := previous.(resolve.CommitStore); ok {
+				if err := store.Store(modulePath, name, c); err != nil {
+					log.WithModule(modulePath).Debugf("failed to store %s using %T: %v", kind, previous, err)
+				}
+			}
+		}
+		opts.recordResolved(modulePath, r, c)
+		log.Debugf("Module path %q resolved to %q ...", modulePath, c.String())
+		return c
+	}
+	return nil
+}
+
+func (opts *Options) resolveByTag(ctx context.Context, modulePath string, name string) *types.Commit {
+	return opts.resolveRef(ctx, resolve.TagRef, modulePath, name)
+}
+
+func (opts *Options) resolveByBranch(ctx context.Context, modulePath string, name string) *types.Commit {
+	return opts.resolveRef(ctx, resolve.BranchRef, modulePath, name)
+}
+
+func (opts *Options) resolveByCommit(ctx context.Context, modulePath string, name string) *types.Commit {
+	return opts.resolveRef(ctx, resolve.CommitRef, modulePath, name)
+}
+
+func (opts *Options) Complete(ctx context.Context) error {
+	if len(opts.KubernetesVersion) > 0 && len(opts.Paths) == 0 {
+		opts.Paths = []string{"k8s.io/*"}
+	}
+	opts.resolutions = newResolutions()
+	if err := opts.parseModules(); err != nil {
+		return err
+	}
+
+	if len(opts.replaces) == 0 {
+		return fmt.Errorf("no modules found with given path prefixes: %#v", opts.Paths)
+	}
+
+	if len(opts.KubernetesVersion) > 0 {
+		return opts.completeKubernetes(ctx)
+	}
+
+	if len(opts.SyncFrom) > 0 {
+		return opts.completeSyncFrom(ctx)
+	}
+
+	if len(opts.Follow) > 0 {
+		return opts.completeFollow(ctx)
+	}
+
+	if len(opts.AlignWith) > 0 {
+		return opts.completeAlign(ctx)
+	}
+
+	groups := opts.groupReplaces()
+	if err := opts.resolveBatch(ctx, groups); err != nil {
+		return err
+	}
+
+	// fail fast cancel resolving of the remaining modules, the parent context tell whether the command was canceled
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < opts.workers(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				group := groups[index]
+				foundCommit := opts.resolveModule(ctx, group.modulePath)
+				for _, modulePath := range group.modulePaths(opts.replaces) {
+					if modulePath != group.modulePath {
+						opts.recordShared(group.modulePath, modulePath)
+					}
+				}
+				// modules canceled are not failed, the cancellation is reported once by returning context error
+				if foundCommit == nil && ctx.Err() == nil {
+					reason := opts.failureReason(group.modulePath)
+					for _, i := range group.indexes {
+						opts.replaces[i].failure = reason
+					}
+					if opts.FailFast {
+						cancel()
+					}
+				}
+				if foundCommit == nil {
+					continue
+				}
+				for _, i := range group.indexes {
+					opts.replaces[i].newPathVersion = foundCommit.String()
+				}
+			}
+		}()
+	}
+	for i := range groups {
+		if ctx.Err() != nil {
+			break
+		}
+		indexes <- i
+	}
+	close(indexes)
+
+	wg.Wait()
+	if parent.Err() != nil {
+		return parent.Err()
+	}
+	if opts.FailFast {
+		for _, r := range opts.replaces {
+			if len(r.failure) > 0 {
+				return fmt.Errorf("failed to resolve %s: %s", r.oldPath, r.failure)
+			}
+		}
+	}
+	opts.logGroupStats(groups)
+	return nil
+}
+
+// workers return the number of modules resolved at once.
+func (opts *Options) workers() int {
+	if opts.Concurrency <= 0 {
+		return DefaultConcurrency
+	}
+	return opts.Concurrency
+}
+
+// resolveModule resolve the module path to commit using the branch, tag or commit set in options.
+func (opts *Options) resolveModule(ctx context.Context, modulePath string) *types.Commit {
+	if c, ok := opts.batched[modulePath]; ok {
+		return c
+	}
+	var foundCommit *types.Commit
+	if len(opts.Branch) > 0 {
+		foundCommit = opts.resolveByBranch(ctx, modulePath, opts.Branch)
+	}
+	if len(opts.Tag) > 0 {
+		foundCommit = opts.resolveByTag(ctx, modulePath, opts.Tag)
+	}
+	if len(opts.Commit) > 0 {
+		foundCommit = opts.resolveByCommit(ctx, modulePath, opts.Commit)
+	}
+	return foundCommit
+}
+
+func (opts *Options) applyReplace(replace moduleReplace) error {
+	cmd := exec.Command("go", "mod", "edit", "-replace", fmt.Sprintf(`%s=%s@%s`, replace.oldPath, replace.newPath, replace.newPathVersion), opts.GoModPath)
+	log.Tracef("Running %q ...", strings.Join(cmd.Args, " "))
+	outBytes, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s: %v\n%s", strings.Join(cmd.Args, " "), err, string(outBytes))
+	}
+	return nil
+}
+
+func (opts *Options) applyRequire(replace moduleReplace) error {
+	cmd := exec.Command("go", "mod", "edit", "-require", fmt.Sprintf(`%s@%s`, replace.newPath, replace.newPathVersion), opts.GoModPath)
+	log.Tracef("Running %q ...", strings.Join(cmd.Args, " "))
+	outBytes, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s: %v\n%s", strings.Join(cmd.Args, " "), err, string(outBytes))
+	}
+	return nil
+}
+
+// commands return the go commands that apply the changes of resolved modules.
+func (opts *Options) commands() []string {
+	commands := []string{}
+	for _, replace := range opts.replaces {
+		switch {
+		case len(replace.newPathVersion) == 0:
+			continue
+		case opts.isWorkspace():
+			commands = append(commands, fmt.Sprintf(`go work edit -replace %s=%s@"%s"`, replace.oldPath, replace.newPath, replace.newPathVersion))
+		case replace.setRequire:
+			commands = append(commands, fmt.Sprintf(`go mod edit -require %s@"%s"`, replace.newPath, replace.newPathVersion))
+		default:
+			commands = append(commands, fmt.Sprintf(`go mod edit -replace %s=%s@"%s"`, replace.oldPath, replace.newPath, replace.newPathVersion))
+		}
+	}
+	return commands
+}
+
+// apply write the changes of resolved modules to go.mod (or go.work) file and update go.sum and vendor as the applier
+// is configured.
+func (opts *Options) apply(ctx context.Context, a *Applier) error {
+	if opts.isWorkspace() {
+		if err := opts.applyWorkReplaces(); err != nil {
+			return err
+		}
+	} else {
+		for _, replace := range opts.replaces {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			switch {
+			case len(replace.newPathVersion) == 0:
+				continue
+			case replace.setRequire:
+				if err := opts.applyRequire(replace); err != nil {
+					return err
+				}
+			default:
+				if err := opts.applyReplace(replace); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if a.UpdateGoSum {
+		if err := opts.updateGoSum(ctx); err != nil {
+			return err
+		}
+	}
+	if opts.isWorkspace() {
+		return nil
+	}
+	if a.Vendor {
+		return opts.updateVendor(ctx)
+	}
+	return opts.reportVendorMismatches()
+}
+
+func (opts *Options) Validate() error {
+	if err := chain.Validate(opts.Resolvers); err != nil {
+		return err
+	}
+	if opts.Offline {
+		if err := chain.ValidateOffline(opts.chainNames()); err != nil {
+			return err
+		}
+	}
+	for _, name := range opts.Resolvers {
+		if name == resolve.ExecKind && len(strings.TrimSpace(opts.ExecResolver)) == 0 {
+			return fmt.Errorf("exec resolver requires the command to be set (--exec-resolver or execResolver in config file)")
+		}
+	}
+	if len(opts.Target) == 0 {
+		opts.Target = TargetGoMod
+	}
+	if opts.Target != TargetGoMod && opts.Target != TargetGoWork {
+		return fmt.Errorf("target must be %q or %q", TargetGoMod, TargetGoWork)
+	}
+	if len(opts.KubernetesVersion) > 0 {
+		if len(opts.Branch) > 0 || len(opts.Commit) > 0 || len(opts.Tag) > 0 || len(opts.AlignWith) > 0 {
+			return fmt.Errorf("kubernetes version cannot be combined with branch, commit, tag or align with")
+		}
+		return nil
+	}
+	if len(opts.Follow) > 0 {
+		if len(opts.Branch) > 0 || len(opts.Commit) > 0 || len(opts.Tag) > 0 || len(opts.AlignWith) > 0 || len(opts.SyncFrom) > 0 {
+			return fmt.Errorf("follow cannot be combined with branch, commit, tag, align with or sync from")
+		}
+	} else if len(opts.SyncFrom) > 0 && isLocalGoMod(opts.SyncFrom) {
+		if len(opts.Branch) > 0 || len(opts.Commit) > 0 || len(opts.Tag) > 0 {
+			return fmt.Errorf("branch, commit or tag cannot be used when syncing from local go.mod file")
+		}
+	} else if len(opts.Branch) == 0 && len(opts.Commit) == 0 && len(opts.Tag) == 0 {
+		return fmt.Errorf("either branch, commit, tag or kubernetes version must be specified")
+	}
+	if len(opts.Paths) == 0 {
+		return fmt.Errorf("dependency name must be specified")
+	}
+	return nil
+}
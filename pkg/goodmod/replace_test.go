@@ -0,0 +1,262 @@
+package goodmod
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/mfojtik/goodmod/pkg/resolve/graphql"
+)
+
+func TestCompleteCanceled(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	goModPath := filepath.Join(dir, "go.mod")
+	if err := ioutil.WriteFile(goModPath, []byte("module example.com/mod\n\nrequire github.com/openshift/api v0.0.0-20191001000000-aaaaaaaaaaaa\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	opts := &Options{GoModPath: goModPath, Target: TargetGoMod, Paths: []string{"github.com/openshift/*"}, Branch: "master"}
+	if err := opts.Complete(ctx); err != context.Canceled {
+		t.Fatalf("expected context canceled error, got %v", err)
+	}
+	if len(opts.replaces) != 1 || len(opts.replaces[0].newPathVersion) > 0 {
+		t.Errorf("expected module not to be resolved: %#v", opts.replaces)
+	}
+}
+
+// fakeGraphQL is a GitHub GraphQL stand-in resolving master branch of all openshift repositories.
+func fakeGraphQL(t *testing.T, requests *int) *httptest.Server {
+	aliases := regexp.MustCompile(`(r\d+): repository\(owner: "openshift", name: "[^"]+"\) \{ ref\(qualifiedName: "refs/heads/master"\)`)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		var body struct {
+			Query string `json:"query"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		data := map[string]interface{}{}
+		for i, m := range aliases.FindAllStringSubmatch(body.Query, -1) {
+			target := map[string]interface{}{"__typename": "Commit", "oid": strings.Repeat(fmt.Sprint(i+1), 40), "committedDate": "2019-10-16T11:04:08Z"}
+			data[m[1]] = map[string]interface{}{"ref": map[string]interface{}{"target": target}}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	}))
+}
+
+func writeGoMod(t *testing.T, dir string, requires ...string) string {
+	goModPath := filepath.Join(dir, "go.mod")
+	goMod := "module example.com/mod\n\nrequire (\n"
+	for _, r := range requires {
+		goMod += "\t" + r + " v0.0.0-20191001000000-aaaaaaaaaaaa\n"
+	}
+	if err := ioutil.WriteFile(goModPath, []byte(goMod+")\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return goModPath
+}
+
+type failingTransport struct{}
+
+func (failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, fmt.Errorf("connection refused")
+}
+
+func TestCompleteBatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var requests int
+	server := fakeGraphQL(t, &requests)
+	defer server.Close()
+
+	opts := &Options{
+		GoModPath:     writeGoMod(t, dir, "github.com/openshift/api", "github.com/openshift/client-go"),
+		Target:        TargetGoMod,
+		Paths:         []string{"github.com/openshift/*"},
+		Branch:        "master",
+		GithubClient:  server.Client(),
+		BatchResolver: graphql.NewGithubBatchResolver(server.Client(), server.URL),
+	}
+	if err := opts.Complete(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 1 {
+		t.Errorf("expected modules resolved by one request, got %d", requests)
+	}
+	for _, replace := range opts.replaces {
+		if replace.newPathVersion != "v0.0.0-20191016110408-111111111111" && replace.newPathVersion != "v0.0.0-20191016110408-222222222222" {
+			t.Errorf("%s: unexpected version %q", replace.newPath, replace.newPathVersion)
+		}
+	}
+}
+
+func TestCompleteFailures(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var requests int
+	server := fakeGraphQL(t, &requests)
+	defer server.Close()
+	goModPath := writeGoMod(t, dir, "github.com/openshift/api", "example.invalid/mod")
+
+	for _, failFast := range []bool{false, true} {
+		opts := &Options{
+			GoModPath:     goModPath,
+			Target:        TargetGoMod,
+			Paths:         []string{"github.com/openshift/*", "example.invalid/*"},
+			Branch:        "master",
+			Concurrency:   1,
+			FailFast:      failFast,
+			GithubClient:  &http.Client{Transport: failingTransport{}},
+			BatchResolver: graphql.NewGithubBatchResolver(server.Client(), server.URL),
+		}
+		err := opts.Complete(context.TODO())
+		if failFast != (err != nil) {
+			t.Errorf("fail fast %t: unexpected error %v", failFast, err)
+		}
+		for _, r := range opts.replaces {
+			switch r.newPath {
+			case "github.com/openshift/api":
+				if len(r.newPathVersion) == 0 || len(r.failure) > 0 {
+					t.Errorf("fail fast %t: expected %s resolved, got %#v", failFast, r.newPath, r)
+				}
+			case "example.invalid/mod":
+				if !strings.Contains(r.failure, "is not a GitHub host") {
+					t.Errorf("fail fast %t: expected %s failure with resolver errors, got %q", failFast, r.newPath, r.failure)
+				}
+			}
+		}
+		if failed := (&Plan{Changes: opts.changes()}).Failed(); len(failed) != 1 {
+			t.Errorf("fail fast %t: expected one failure, got %#v", failFast, failed)
+		}
+	}
+}
+
+func TestGroupReplaces(t *testing.T) {
+	opts := &Options{Branch: "master", replaces: []moduleReplace{
+		{oldPath: "k8s.io/api", newPath: "github.com/kubernetes/kubernetes/staging/src/k8s.io/api"},
+		{oldPath: "github.com/openshift/api/tools", newPath: "github.com/openshift/api/tools"},
+		{oldPath: "k8s.io/apimachinery", newPath: "github.com/kubernetes/kubernetes/staging/src/k8s.io/apimachinery"},
+		{oldPath: "github.com/openshift/api", newPath: "github.com/openshift/api"},
+		{oldPath: "golang.org/x/net", newPath: "golang.org/x/net"},
+	}}
+	groups := opts.groupReplaces()
+	expected := []struct {
+		modulePath string
+		indexes    []int
+	}{
+		{modulePath: "github.com/kubernetes/kubernetes/staging/src/k8s.io/api", indexes: []int{0, 2}},
+		{modulePath: "github.com/openshift/api", indexes: []int{1, 3}},
+		{modulePath: "golang.org/x/net", indexes: []int{4}},
+	}
+	if len(groups) != len(expected) {
+		t.Fatalf("expected %d groups, got %#v", len(expected), groups)
+	}
+	for i, g := range groups {
+		if g.modulePath != expected[i].modulePath || fmt.Sprint(g.indexes) != fmt.Sprint(expected[i].indexes) {
+			t.Errorf("expected group %#v, got %#v", expected[i], g)
+		}
+	}
+}
+
+func TestResolveRefStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer os.Setenv("XDG_CACHE_HOME", os.Getenv("XDG_CACHE_HOME"))
+	os.Setenv("XDG_CACHE_HOME", dir)
+	script := filepath.Join(dir, "resolve-ref")
+	if err := ioutil.WriteFile(script, []byte("#!/bin/sh\necho '{\"sha\": \"c07a134afb4217bd3dc3cd89a6e5eb5b1a2c4b6e\", \"timestamp\": \"2019-10-16T11:51:29Z\"}'\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := &Options{Resolvers: []string{"cache", "exec"}, ExecResolver: script, resolutions: newResolutions()}
+	if c := opts.resolveByTag(context.TODO(), "git.corp/team/lib", "v1.0.0"); c == nil || c.String() != "v0.0.0-20191016115129-c07a134afb42" {
+		t.Fatalf("unexpected commit %v", c)
+	}
+	// the exec resolver fails now, the commit must be resolved from cache
+	opts = &Options{Resolvers: []string{"cache", "exec"}, ExecResolver: "false", resolutions: newResolutions()}
+	if c := opts.resolveByTag(context.TODO(), "git.corp/team/lib", "v1.0.0"); c == nil || c.String() != "v0.0.0-20191016115129-c07a134afb42" {
+		t.Fatalf("unexpected commit %v", c)
+	}
+	if res, _ := opts.resolvedModule("git.corp/team/lib"); res.resolver != "cache.CacheResolver" {
+		t.Errorf("expected commit resolved from cache, got %q", res.resolver)
+	}
+}
+
+func TestCompleteOffline(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer os.Setenv("XDG_CACHE_HOME", os.Getenv("XDG_CACHE_HOME"))
+	os.Setenv("XDG_CACHE_HOME", dir)
+
+	src := filepath.Join(dir, "src")
+	repository := filepath.Join(src, "github.com", "openshift", "api")
+	if err := os.MkdirAll(repository, 0755); err != nil {
+		t.Fatal(err)
+	}
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repository
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Skipf("git %s: %v: %s", strings.Join(args, " "), err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "-q", "-b", "release-4.6")
+	git("commit", "-q", "--allow-empty", "-m", "initial")
+	sha := git("rev-parse", "HEAD")
+
+	opts := &Options{
+		GoModPath:   writeGoMod(t, dir, "github.com/openshift/api"),
+		Target:      TargetGoMod,
+		Paths:       []string{"github.com/openshift/*"},
+		Branch:      "release-4.6",
+		Concurrency: 1,
+		Offline:     true,
+		LocalRepos:  []string{src},
+		// requests to GitHub would fail the test
+		GithubClient: &http.Client{Transport: failingTransport{}},
+	}
+	if err := opts.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := opts.Complete(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	if len(opts.replaces) != 1 || !strings.HasSuffix(opts.replaces[0].newPathVersion, sha[:12]) {
+		t.Errorf("expected module resolved to %s from local clone, got %#v", sha, opts.replaces)
+	}
+
+	opts.Resolvers = []string{"local", "github"}
+	if err := opts.Validate(); err == nil {
+		t.Errorf("expected github resolver to be rejected offline")
+	}
+}
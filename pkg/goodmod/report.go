@@ -0,0 +1,325 @@
+package goodmod
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4"
+
+	"github.com/mfojtik/goodmod/pkg/config"
+	"github.com/mfojtik/goodmod/pkg/golang"
+	"github.com/mfojtik/goodmod/pkg/log"
+	"github.com/mfojtik/goodmod/pkg/resolve"
+	"github.com/mfojtik/goodmod/pkg/resolve/branch"
+	"github.com/mfojtik/goodmod/pkg/resolve/graphql"
+	"github.com/mfojtik/goodmod/pkg/resolve/types"
+)
+
+// Tracking types of modules, the rule source (eg. 'branch' or 'tag') is used for modules matching a rule.
+const (
+	TrackingManual   = "manual"
+	TrackingRequired = "required"
+	TrackingLocal    = "local"
+)
+
+// ModuleStatus is the current and desired version of a module required or replaced by go.mod (or go.work) file.
+type ModuleStatus struct {
+	Path string `json:"path"`
+	// ReplacePath is the module path or local directory the module is replaced with
+	ReplacePath string `json:"replacePath,omitempty"`
+	// CurrentVersion is the abbreviated commit of pseudo-versions, the tag otherwise
+	CurrentVersion string `json:"currentVersion"`
+	// TrackingType is the rule source (eg. 'branch' or 'tag'), 'manual' for modules replaced without rule, 'required'
+	// for modules only required and 'local' for modules replaced by local directory
+	TrackingType   string `json:"trackingType"`
+	DesiredVersion string `json:"desiredVersion,omitempty"`
+	// Updates describe the commits the current version is missing (eg. '3 commits' or 'up to date'), it is only set for
+	// modules tracking branch
+	Updates string `json:"updates,omitempty"`
+	// UsedBy are the workspace modules directories (or go.work) that require or replace the module
+	UsedBy []string `json:"usedBy,omitempty"`
+}
+
+// Reporter report the current and desired versions of all modules the go.mod (or go.work) file require or replace,
+// using the rules of the config file.
+type Reporter struct {
+	Options
+}
+
+// Report return the status of all modules. The config file must exist.
+func (r *Reporter) Report(ctx context.Context) ([]ModuleStatus, error) {
+	c, err := config.ReadRules(r.ConfigPath, r.GoModPath)
+	if err != nil {
+		return nil, err
+	}
+	opts := r.Options
+	if err := opts.defaultClients(c.GithubHosts); err != nil {
+		return nil, err
+	}
+
+	var modules []ModuleStatus
+	switch opts.Target {
+	case "", TargetGoMod:
+		modules, err = opts.statusModules(c.Rules)
+	case TargetGoWork:
+		modules, err = opts.statusWorkspaceModules(c.Rules)
+	default:
+		err = fmt.Errorf("target must be %q or %q", TargetGoMod, TargetGoWork)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := c.ResolverTimeouts[resolve.GithubKind]
+	heads := opts.branchHeads(ctx, modules, timeout)
+	for i, m := range modules {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if m.TrackingType != "branch" {
+			continue
+		}
+		// modules already at the branch head don't need the commits compared
+		head, ok := heads[m.ReplacePath+"@"+m.DesiredVersion]
+		if ok && len(m.CurrentVersion) > 0 && strings.HasPrefix(head.SHA, m.CurrentVersion) {
+			modules[i].Updates = "up to date"
+			continue
+		}
+		modules[i].Updates = m.commitsMissing(ctx, opts.githubHosts(), timeout)
+	}
+	return modules, nil
+}
+
+// defaultClients set the GitHub clients that are not set, the GITHUB_TOKEN is used for github.com and the token
+// environment variables of hosts for GitHub Enterprise Server.
+func (opts *Options) defaultClients(hosts []config.GithubHost) error {
+	if opts.GithubClient == nil {
+		opts.GithubClient = resolve.NewGithubClient(githubToken(), opts.Retries)
+	}
+	if opts.GithubHosts == nil {
+		githubHosts, err := resolve.NewGithubHosts(opts.GithubClient, hosts, opts.Retries)
+		if err != nil {
+			return err
+		}
+		opts.GithubHosts = githubHosts
+	}
+	if opts.BatchResolver == nil && len(githubToken()) > 0 {
+		opts.BatchResolver = graphql.NewGithubBatchResolver(opts.GithubClient, "")
+	}
+	return nil
+}
+
+// commitsMissing list commits missing in the current version, the timeout limit the duration of listing for this module.
+func (m ModuleStatus) commitsMissing(ctx context.Context, hosts *resolve.GithubHosts, timeout time.Duration) string {
+	lister := branch.NewGithubBranchCommitsLister(hosts)
+	ctx, cancel := resolve.WithTimeout(ctx, timeout)
+	defer cancel()
+	commits, err := lister.List(ctx, m.ReplacePath, m.CurrentVersion, m.DesiredVersion)
+	if err != nil {
+		return err.Error()
+	}
+	if commits == 0 {
+		return "up to date"
+	}
+	return fmt.Sprintf("%d commits", commits)
+}
+
+func formatModuleVersion(v string) string {
+	// v0.0.0-20191016115129-c07a134afb42 => c07a134afb42
+	parts := strings.Split(v, "-")
+	if len(parts) == 3 {
+		return strings.TrimSuffix(parts[2], "+incompatible")
+	}
+	return strings.TrimSuffix(v, "+incompatible")
+}
+
+// branchHeads resolve heads of branches tracked by GitHub hosted modules using the batch resolver. Modules that failed
+// to resolve are not included.
+func (opts *Options) branchHeads(ctx context.Context, modules []ModuleStatus, timeout time.Duration) map[string]*types.Commit {
+	heads := map[string]*types.Commit{}
+	if opts.BatchResolver == nil {
+		return heads
+	}
+	refs := []graphql.Ref{}
+	for _, m := range modules {
+		if m.TrackingType == "branch" && resolve.IsGithubModule(m.ReplacePath) {
+			refs = append(refs, graphql.Ref{ModulePath: m.ReplacePath, Kind: resolve.BranchRef, Name: m.DesiredVersion})
+		}
+	}
+	if len(refs) == 0 {
+		return heads
+	}
+	ctx, cancel := resolve.WithTimeout(ctx, timeout)
+	defer cancel()
+	results, err := opts.BatchResolver.ResolveAll(ctx, refs)
+	if err != nil {
+		log.Debugf("Failed to resolve branches using GitHub GraphQL API: %v", err)
+	}
+	for ref, result := range results {
+		if result.Err != nil {
+			log.WithModule(ref.ModulePath).Debugf("failed to resolve branch %q: %v", ref.Name, result.Err)
+			continue
+		}
+		heads[ref.ModulePath+"@"+ref.Name] = result.Commit
+	}
+	return heads
+}
+
+// statusModules parse the go.mod file and return the status of all required and replaced modules.
+func (opts *Options) statusModules(rules []config.Rule) ([]ModuleStatus, error) {
+	modBytes, err := ioutil.ReadFile(opts.GoModPath)
+	if err != nil {
+		return nil, err
+	}
+	s, err := golang.ParseModFile("go.mod", modBytes, nil)
+	if err != nil {
+		return nil, err
+	}
+	modules := []ModuleStatus{}
+	for _, r := range s.Replace {
+		if golang.IsDirectoryPath(r.New.Path) {
+			modules = append(modules, newLocalModule(r.Old.Path, r.New.Path, filepath.Dir(opts.GoModPath)))
+			continue
+		}
+		modules = append(modules, newReplacedModule(r.Old.Path, r.New.Path, r.New.Version, rules))
+	}
+	for _, r := range s.Require {
+		foundReplace := false
+		for _, m := range modules {
+			if m.Path == r.Mod.Path {
+				foundReplace = true
+				break
+			}
+		}
+		if foundReplace {
+			continue
+		}
+		modules = append(modules, newRequiredModule(r.Mod.Path, r.Mod.Version))
+	}
+	return modules, nil
+}
+
+func newReplacedModule(path, replacePath, replaceVersion string, rules []config.Rule) ModuleStatus {
+	newModule := ModuleStatus{
+		Path:           path,
+		ReplacePath:    replacePath,
+		CurrentVersion: formatModuleVersion(replaceVersion),
+	}
+	if rule := config.RuleForPath(rules, path); rule != nil {
+		trackingType, version := formatRuleSource(*rule)
+		newModule.DesiredVersion = version
+		newModule.TrackingType = trackingType
+	} else {
+		newModule.DesiredVersion = formatModuleVersion(replaceVersion)
+		newModule.TrackingType = TrackingManual
+	}
+	return newModule
+}
+
+func newRequiredModule(path, version string) ModuleStatus {
+	return ModuleStatus{
+		Path:           path,
+		CurrentVersion: formatModuleVersion(version),
+		TrackingType:   TrackingRequired,
+	}
+}
+
+// newLocalModule return the module replaced by local directory (eg. by 'goodmod link'), relative directory is resolved
+// against the base directory.
+func newLocalModule(path, dir, baseDir string) ModuleStatus {
+	localDir := dir
+	if !filepath.IsAbs(localDir) {
+		localDir = filepath.Join(baseDir, localDir)
+	}
+	return ModuleStatus{
+		Path:           path,
+		ReplacePath:    dir,
+		CurrentVersion: localHead(localDir),
+		DesiredVersion: dir,
+		TrackingType:   TrackingLocal,
+	}
+}
+
+// localHead return the abbreviated SHA of the local checkout HEAD, marked dirty when the worktree has changes.
+func localHead(dir string) string {
+	repository, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		log.Infof("Unable to open local directory %q: %v", dir, err)
+		return "unknown"
+	}
+	head, err := repository.Head()
+	if err != nil {
+		log.Infof("Unable to read HEAD of %q: %v", dir, err)
+		return "unknown"
+	}
+	// abbreviated as in pseudo-versions
+	version := head.Hash().String()[:12]
+	worktree, err := repository.Worktree()
+	if err != nil {
+		return version
+	}
+	if status, err := worktree.Status(); err == nil && !status.IsClean() {
+		version += " (dirty)"
+	}
+	return version
+}
+
+// statusWorkspaceModules parse the go.work file and go.mod files of all modules it use and return the status of all
+// modules together. Replacements in go.work take priority over replacements in go.mod files.
+func (opts *Options) statusWorkspaceModules(rules []config.Rule) ([]ModuleStatus, error) {
+	work, workspaceModules, err := golang.ReadWorkspace(opts.GoWorkPath)
+	if err != nil {
+		return nil, err
+	}
+	usedBy := map[string][]string{}
+	for _, r := range work.Replace {
+		usedBy[r.Old.Path] = []string{"go.work"}
+	}
+	for _, wm := range workspaceModules {
+		for path := range wm.Modules {
+			usedBy[path] = append(usedBy[path], wm.Dir)
+		}
+	}
+	modules := []ModuleStatus{}
+	for path, m := range golang.WorkspaceVersions(work, workspaceModules) {
+		var newModule ModuleStatus
+		switch {
+		case golang.IsDirectoryPath(m.ReplacePath):
+			newModule = newLocalModule(path, m.ReplacePath, filepath.Dir(opts.GoWorkPath))
+		case len(m.ReplacePath) > 0:
+			newModule = newReplacedModule(path, m.ReplacePath, m.ReplaceVersion, rules)
+		default:
+			newModule = newRequiredModule(path, m.Version)
+		}
+		newModule.UsedBy = usedBy[path]
+		modules = append(modules, newModule)
+	}
+	return modules, nil
+}
+
+func formatRuleSource(rule config.Rule) (string, string) {
+	switch {
+	case len(rule.Follow) > 0:
+		return "follow", rule.Follow
+	case len(rule.KubernetesVersion) > 0:
+		return "kubernetes", rule.KubernetesVersion
+	case len(rule.AlignWith) > 0:
+		return "align", rule.AlignWith
+	case len(rule.SyncFrom) > 0:
+		return "sync", rule.SyncFrom
+	case len(rule.Commit) > 12:
+		return "commit", rule.Commit[0:12]
+	case len(rule.Commit) > 0:
+		return "commit", rule.Commit
+	case len(rule.TagName) > 0:
+		return "tag", rule.TagName
+	case len(rule.BranchName) > 0:
+		return "branch", rule.BranchName
+	default:
+		return "<unknown>", "<unknown>"
+	}
+}
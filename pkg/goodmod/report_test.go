@@ -0,0 +1,30 @@
+package goodmod
+
+import (
+	"testing"
+
+	"github.com/mfojtik/goodmod/pkg/config"
+)
+
+func TestFormatRuleSource(t *testing.T) {
+	for _, tc := range []struct {
+		rule         config.Rule
+		trackingType string
+		source       string
+	}{
+		{rule: config.Rule{BranchName: "master"}, trackingType: "branch", source: "master"},
+		{rule: config.Rule{TagName: "v1.0.0"}, trackingType: "tag", source: "v1.0.0"},
+		{rule: config.Rule{Commit: "3d4e2ac1b2ad0e0a5a6b9c14a0b9a1ec8b41a5e4"}, trackingType: "commit", source: "3d4e2ac1b2ad"},
+		// the abbreviated commit (eg. set by '// goodmod: commit=abc123' annotation) is not truncated
+		{rule: config.Rule{Commit: "abc123"}, trackingType: "commit", source: "abc123"},
+		// the modules aligned or synced with other module are not tracking the branch, even when it is set
+		{rule: config.Rule{AlignWith: "github.com/openshift/library-go", BranchName: "master"}, trackingType: "align", source: "github.com/openshift/library-go"},
+		{rule: config.Rule{SyncFrom: "github.com/openshift/origin", BranchName: "master"}, trackingType: "sync", source: "github.com/openshift/origin"},
+		{rule: config.Rule{}, trackingType: "<unknown>", source: "<unknown>"},
+	} {
+		trackingType, source := formatRuleSource(tc.rule)
+		if trackingType != tc.trackingType || source != tc.source {
+			t.Errorf("expected %s %q, got %s %q", tc.trackingType, tc.source, trackingType, source)
+		}
+	}
+}
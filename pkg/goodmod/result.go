@@ -0,0 +1,163 @@
+package goodmod
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mfojtik/goodmod/pkg/config"
+	"github.com/mfojtik/goodmod/pkg/resolve/types"
+)
+
+// ResolverError is an error returned by a single resolver while resolving the module.
+type ResolverError struct {
+	Resolver string `json:"resolver"`
+	Error    string `json:"error"`
+}
+
+// Change is the planned change of a single module. Modules that are already at the resolved version and modules that
+// failed to resolve are included as well.
+type Change struct {
+	OldPath    string `json:"oldPath"`
+	OldVersion string `json:"oldVersion,omitempty"`
+	NewPath    string `json:"newPath"`
+	NewVersion string `json:"newVersion,omitempty"`
+	// Directive is either 'replace' or 'require'
+	Directive string `json:"directive"`
+	Changed   bool   `json:"changed"`
+
+	// SHA and Time are set when the module commit was resolved directly by a resolver
+	SHA  string     `json:"sha,omitempty"`
+	Time *time.Time `json:"time,omitempty"`
+
+	Rule     config.Rule     `json:"rule"`
+	Resolver string          `json:"resolver,omitempty"`
+	Errors   []ResolverError `json:"errors,omitempty"`
+	// Failure is the reason the module failed to resolve
+	Failure string `json:"failure,omitempty"`
+	// Skipped is the reason the module keeps its version without failing (eg. the sync source does not require it)
+	Skipped string `json:"skipped,omitempty"`
+}
+
+// resolution record the resolvers outcome for a module path.
+type resolution struct {
+	commit   *types.Commit
+	resolver string
+	errors   []ResolverError
+}
+
+func resolverName(r interface{}) string {
+	return strings.TrimPrefix(fmt.Sprintf("%T", r), "*")
+}
+
+// resolutions record the resolvers outcome for all resolved module paths. It is safe to use from multiple goroutines.
+type resolutions struct {
+	sync.Mutex
+	modules map[string]*resolution
+}
+
+func newResolutions() *resolutions {
+	return &resolutions{modules: map[string]*resolution{}}
+}
+
+func (r *resolutions) get(modulePath string) *resolution {
+	if _, ok := r.modules[modulePath]; !ok {
+		r.modules[modulePath] = &resolution{}
+	}
+	return r.modules[modulePath]
+}
+
+// recordResolverError record the error of resolver for module path.
+func (opts *Options) recordResolverError(modulePath string, resolver interface{}, err error) {
+	if opts.resolutions == nil {
+		return
+	}
+	opts.resolutions.Lock()
+	defer opts.resolutions.Unlock()
+	r := opts.resolutions.get(modulePath)
+	r.errors = append(r.errors, ResolverError{Resolver: resolverName(resolver), Error: err.Error()})
+}
+
+// recordResolved record the resolver that resolved the module path.
+func (opts *Options) recordResolved(modulePath string, resolver interface{}, c *types.Commit) {
+	if opts.resolutions == nil {
+		return
+	}
+	opts.resolutions.Lock()
+	defer opts.resolutions.Unlock()
+	r := opts.resolutions.get(modulePath)
+	r.commit = c
+	r.resolver = resolverName(resolver)
+}
+
+// recordShared record the module path was resolved together with another module path from the same repository.
+func (opts *Options) recordShared(fromModulePath, modulePath string) {
+	if opts.resolutions == nil {
+		return
+	}
+	opts.resolutions.Lock()
+	defer opts.resolutions.Unlock()
+	from, r := opts.resolutions.get(fromModulePath), opts.resolutions.get(modulePath)
+	r.commit, r.resolver, r.errors = from.commit, from.resolver, from.errors
+}
+
+// ruleForOutput return the rule that produced these options, for options created from flags the rule is built from
+// flags.
+func (opts *Options) ruleForOutput() config.Rule {
+	if opts.rule != nil {
+		return *opts.rule
+	}
+	return config.Rule{
+		Paths:             opts.Paths,
+		Excludes:          opts.Excludes,
+		BranchName:        opts.Branch,
+		TagName:           opts.Tag,
+		Commit:            opts.Commit,
+		AlignWith:         opts.AlignWith,
+		KubernetesVersion: opts.KubernetesVersion,
+		SyncFrom:          opts.SyncFrom,
+		Follow:            opts.Follow,
+		Resolvers:         opts.Resolvers,
+	}
+}
+
+// changes return the planned changes of all matching modules.
+func (opts *Options) changes() []Change {
+	results := []Change{}
+	for _, r := range opts.replaces {
+		result := Change{
+			OldPath:    r.oldPath,
+			OldVersion: r.oldPathVersion,
+			NewPath:    r.newPath,
+			NewVersion: r.newPathVersion,
+			Directive:  "replace",
+			Changed:    len(r.newPathVersion) > 0 && (r.newPath != r.oldTargetPath || r.newPathVersion != r.oldPathVersion),
+			Rule:       opts.ruleForOutput(),
+			Failure:    r.failure,
+			Skipped:    r.skipped,
+		}
+		if r.setRequire {
+			result.Directive = "require"
+		}
+		if res, ok := opts.resolvedModule(r.newPath); ok {
+			result.Resolver = res.resolver
+			result.Errors = res.errors
+			if res.commit != nil && res.commit.String() == r.newPathVersion {
+				result.SHA = res.commit.SHA
+				t := res.commit.Timestamp.UTC()
+				result.Time = &t
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func (opts *Options) resolvedModule(modulePath string) (*resolution, bool) {
+	if opts.resolutions == nil {
+		return nil, false
+	}
+	res, ok := opts.resolutions.modules[modulePath]
+	return res, ok
+}
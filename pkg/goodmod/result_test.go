@@ -0,0 +1,44 @@
+package goodmod
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mfojtik/goodmod/pkg/resolve/branch"
+	"github.com/mfojtik/goodmod/pkg/resolve/types"
+)
+
+func TestChanges(t *testing.T) {
+	c := &types.Commit{SHA: "c07a134afb42e6ef4ecbff1e43b7eb5af1d6a4a8", Timestamp: time.Date(2019, 10, 16, 11, 51, 29, 0, time.UTC)}
+	opts := &Options{
+		Paths:       []string{"github.com/openshift/*"},
+		Branch:      "master",
+		resolutions: newResolutions(),
+		replaces: []moduleReplace{
+			{oldPath: "github.com/openshift/api", newPath: "github.com/openshift/api", oldTargetPath: "github.com/openshift/api", oldPathVersion: "v0.0.0-20191001000000-aaaaaaaaaaaa", newPathVersion: c.String()},
+			{oldPath: "github.com/openshift/client-go", newPath: "github.com/openshift/client-go", oldTargetPath: "github.com/openshift/client-go", required: true, setRequire: true},
+		},
+	}
+	opts.recordResolverError("github.com/openshift/api", &branch.GithubBranchResolver{}, fmt.Errorf("rate limited"))
+	opts.recordResolved("github.com/openshift/api", &branch.GitBranchResolver{}, c)
+
+	results := opts.changes()
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	api := results[0]
+	if !api.Changed || api.Directive != "replace" || api.SHA != c.SHA || api.Time == nil || !api.Time.Equal(c.Timestamp) {
+		t.Errorf("unexpected result: %#v", api)
+	}
+	if api.Resolver != "branch.GitBranchResolver" || len(api.Errors) != 1 || api.Errors[0].Resolver != "branch.GithubBranchResolver" {
+		t.Errorf("unexpected resolvers: %q %#v", api.Resolver, api.Errors)
+	}
+	if api.Rule.BranchName != "master" || api.Rule.Paths[0] != "github.com/openshift/*" {
+		t.Errorf("expected rule built from flags, got %#v", api.Rule)
+	}
+	clientGo := results[1]
+	if clientGo.Changed || clientGo.Directive != "require" || len(clientGo.SHA) > 0 {
+		t.Errorf("unexpected result: %#v", clientGo)
+	}
+}
@@ -0,0 +1,108 @@
+package goodmod
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mfojtik/goodmod/pkg/golang"
+	"github.com/mfojtik/goodmod/pkg/log"
+	"github.com/mfojtik/goodmod/pkg/resolve"
+	"github.com/mfojtik/goodmod/pkg/resolve/modsource"
+	"github.com/mfojtik/goodmod/pkg/resolve/types"
+)
+
+// fetchModule fetch the module version content from module proxy or build it from git repository.
+func (opts *Options) fetchModule(ctx context.Context, modulePath, version string) (*types.Module, error) {
+	fetchers := []resolve.ModuleFetcher{
+		modsource.NewProxyModuleFetcher(),
+		modsource.NewGitModuleFetcher(opts.GitAuth),
+	}
+	switch {
+	case opts.Offline:
+		fetchers = []resolve.ModuleFetcher{modsource.NewLocalModuleFetcher(opts.localRepos())}
+	case len(opts.LocalRepos) > 0:
+		fetchers = append([]resolve.ModuleFetcher{modsource.NewLocalModuleFetcher(opts.LocalRepos)}, fetchers...)
+	}
+	log.Infof("Fetching module %s@%s ...", modulePath, version)
+	for _, f := range fetchers {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		fetcherCtx, cancel := opts.resolverContext(ctx, f)
+		m, err := f.Fetch(fetcherCtx, modulePath, version)
+		cancel()
+		if err != nil {
+			log.WithModule(modulePath).Errorf("failed to fetch module using %T: %v", f, err)
+			continue
+		}
+		return m, nil
+	}
+	return nil, fmt.Errorf("unable to fetch module %s@%s", modulePath, version)
+}
+
+// updateGoSum add hashes of the new replace targets to go.sum (or go.work.sum) file and remove hashes of the old versions
+// the target (already written) no longer require or replace. The hashes of modules that were fetched are written even
+// when other modules failed, the failed modules are returned in the error and their old hashes are kept.
+func (opts *Options) updateGoSum(ctx context.Context) error {
+	goSumPath := opts.sumPath()
+	sum, err := golang.ReadGoSum(goSumPath)
+	if err != nil {
+		return err
+	}
+	modules, err := opts.targetModules()
+	if err != nil {
+		return err
+	}
+	used := map[string]bool{}
+	for _, m := range modules {
+		used[m.Path+"@"+m.Version] = true
+		if len(m.ReplacePath) > 0 {
+			used[m.ReplacePath+"@"+m.ReplaceVersion] = true
+		}
+	}
+
+	failed := []string{}
+	for _, r := range opts.replaces {
+		if len(r.newPathVersion) == 0 || (r.newPath == r.oldTargetPath && r.newPathVersion == r.oldPathVersion) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := opts.addModuleSum(ctx, sum, r.newPath, r.newPathVersion); err != nil {
+			log.WithModule(r.oldPath).Errorf("unable to update go.sum: %v", err)
+			failed = append(failed, r.newPath+"@"+r.newPathVersion)
+			continue
+		}
+		if len(r.oldPathVersion) > 0 && !used[r.oldTargetPath+"@"+r.oldPathVersion] {
+			sum.Drop(r.oldTargetPath, r.oldPathVersion)
+		}
+	}
+	if err := sum.Write(goSumPath); err != nil {
+		return err
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("unable to update %s for %s", goSumPath, strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// addModuleSum fetch the module version and set its module and go.mod file hashes.
+func (opts *Options) addModuleSum(ctx context.Context, sum *golang.GoSum, modulePath, version string) error {
+	m, err := opts.fetchModule(ctx, modulePath, version)
+	if err != nil {
+		return err
+	}
+	hash, err := modsource.Hash(m)
+	if err != nil {
+		return fmt.Errorf("unable to hash module: %v", err)
+	}
+	goModHash, err := modsource.HashGoMod(m)
+	if err != nil {
+		return fmt.Errorf("unable to hash go.mod: %v", err)
+	}
+	sum.Set(modulePath, version, hash)
+	sum.Set(modulePath, version+"/go.mod", goModHash)
+	return nil
+}
@@ -0,0 +1,101 @@
+package goodmod
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUpdateGoSum(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sum")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	src := filepath.Join(dir, "src")
+	repository := filepath.Join(src, "github.com", "openshift", "api")
+	if err := os.MkdirAll(repository, 0755); err != nil {
+		t.Fatal(err)
+	}
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repository
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Skipf("git %s: %v: %s", strings.Join(args, " "), err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "-q")
+	if err := ioutil.WriteFile(filepath.Join(repository, "go.mod"), []byte("module github.com/openshift/api\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "go.mod")
+	git("commit", "-q", "-m", "initial")
+	newVersion := "v0.0.0-20191105131421-" + git("rev-parse", "HEAD")[:12]
+
+	oldSum := "github.com/openshift/api v0.0.0-20191001000000-aaaaaaaaaaaa h1:old=\n" +
+		"github.com/openshift/api v0.0.0-20191001000000-aaaaaaaaaaaa/go.mod h1:old=\n" +
+		"github.com/openshift/client-go v0.0.0-20191001000000-aaaaaaaaaaaa h1:old=\n" +
+		"github.com/openshift/client-go v0.0.0-20191001000000-aaaaaaaaaaaa/go.mod h1:old=\n" +
+		"github.com/openshift/library-go v0.0.0-20191001000000-aaaaaaaaaaaa h1:old=\n" +
+		"github.com/openshift/library-go v0.0.0-20191001000000-aaaaaaaaaaaa/go.mod h1:old=\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "go.sum"), []byte(oldSum), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// go.mod is already written when go.sum is updated
+	goMod := `module example.com/test
+
+require (
+	github.com/openshift/api ` + newVersion + `
+	github.com/openshift/client-go v0.0.0-20191001000000-aaaaaaaaaaaa
+	github.com/openshift/library-go v0.0.0-20191001000000-aaaaaaaaaaaa
+)
+
+replace github.com/openshift/library-go => github.com/openshift/api ` + newVersion + `
+`
+	if err := ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0644); err != nil {
+		t.Fatal(err)
+	}
+	opts := &Options{
+		GoModPath:  filepath.Join(dir, "go.mod"),
+		Target:     TargetGoMod,
+		Offline:    true,
+		LocalRepos: []string{src},
+		replaces: []moduleReplace{
+			{oldPath: "github.com/openshift/api", oldTargetPath: "github.com/openshift/api", oldPathVersion: "v0.0.0-20191001000000-aaaaaaaaaaaa", newPath: "github.com/openshift/api", newPathVersion: newVersion, required: true, setRequire: true},
+			// there is no local clone of client-go
+			{oldPath: "github.com/openshift/client-go", oldTargetPath: "github.com/openshift/client-go", oldPathVersion: "v0.0.0-20191001000000-aaaaaaaaaaaa", newPath: "github.com/openshift/client-go", newPathVersion: newVersion},
+			// the replaced module is still required at the old version
+			{oldPath: "github.com/openshift/library-go", oldTargetPath: "github.com/openshift/library-go", oldPathVersion: "v0.0.0-20191001000000-aaaaaaaaaaaa", newPath: "github.com/openshift/api", newPathVersion: newVersion},
+		},
+	}
+	err = opts.updateGoSum(context.TODO())
+	if err == nil || !strings.Contains(err.Error(), "github.com/openshift/client-go@"+newVersion) || strings.Contains(err.Error(), "github.com/openshift/api@") {
+		t.Errorf("expected client-go failure, got %v", err)
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, "go.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(oldSum), "\n") {
+		// the old api version is no longer used by go.mod, the failed client-go and the required library-go are kept
+		dropped := strings.HasPrefix(line, "github.com/openshift/api ")
+		if strings.Contains(string(content), line+"\n") == dropped {
+			t.Errorf("expected %q dropped=%t in go.sum, got:\n%s", line, dropped, content)
+		}
+	}
+	if !strings.Contains(string(content), "github.com/openshift/api "+newVersion+" h1:") || !strings.Contains(string(content), "github.com/openshift/api "+newVersion+"/go.mod h1:") {
+		t.Errorf("expected hashes of %s added, got:\n%s", newVersion, content)
+	}
+	if strings.Contains(string(content), "github.com/openshift/client-go "+newVersion) {
+		t.Errorf("expected no hashes of failed client-go, got:\n%s", content)
+	}
+}
@@ -0,0 +1,19 @@
+package goodmod
+
+import (
+	"fmt"
+	"strings"
+)
+
+// failureReason return why the module path failed to resolve, using errors of resolvers when they were recorded.
+func (opts *Options) failureReason(modulePath string) string {
+	res, ok := opts.resolvedModule(modulePath)
+	if !ok || len(res.errors) == 0 {
+		return "unable to get commit"
+	}
+	reasons := []string{}
+	for _, e := range res.errors {
+		reasons = append(reasons, fmt.Sprintf("%s: %s", e.Resolver, e.Error))
+	}
+	return strings.Join(reasons, "; ")
+}
@@ -0,0 +1,86 @@
+package goodmod
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/mfojtik/goodmod/pkg/golang"
+	"github.com/mfojtik/goodmod/pkg/log"
+)
+
+// isLocalGoMod return true when the sync source is a local go.mod file or directory with go.mod file.
+func isLocalGoMod(source string) bool {
+	return filepath.Base(source) == "go.mod" || golang.IsDirectoryPath(source)
+}
+
+// syncSourceModules return modules required by the sync source go.mod file.
+func (opts *Options) syncSourceModules(ctx context.Context) (map[string]golang.ModuleVersion, string, error) {
+	if isLocalGoMod(opts.SyncFrom) {
+		goModPath := opts.SyncFrom
+		if filepath.Base(goModPath) != "go.mod" {
+			goModPath = filepath.Join(goModPath, "go.mod")
+		}
+		content, err := ioutil.ReadFile(goModPath)
+		if err != nil {
+			return nil, "", err
+		}
+		modules, err := golang.UpstreamVersions(goModPath, content)
+		return modules, goModPath, err
+	}
+	// allow repository URL (https://github.com/openshift/origin.git) to be used as module path
+	modulePath := strings.TrimSuffix(strings.TrimPrefix(opts.SyncFrom, "https://"), ".git")
+	c := opts.resolveModule(ctx, modulePath)
+	if c == nil {
+		return nil, "", fmt.Errorf("unable to resolve %q", modulePath)
+	}
+	modules, err := opts.fetchGoMod(ctx, modulePath, c)
+	return modules, modulePath + "@" + c.String(), err
+}
+
+// completeSyncFrom copy versions and replace targets of matching modules from the sync source go.mod file.
+// Modules the source does not require or replace by local directory are skipped, modules that would be downgraded are
+// reported as failed.
+func (opts *Options) completeSyncFrom(ctx context.Context) error {
+	upstream, source, err := opts.syncSourceModules(ctx)
+	if err != nil {
+		return err
+	}
+	synced, skipped, conflicts := 0, 0, 0
+	for i, r := range opts.replaces {
+		m, ok := upstream[r.oldPath]
+		if !ok {
+			opts.replaces[i].skipped = fmt.Sprintf("module is not required by %s", source)
+			log.WithModule(r.oldPath).Debugf("skipped: %s", opts.replaces[i].skipped)
+			skipped++
+			continue
+		}
+		path, version := m.Target()
+		if golang.IsDirectoryPath(path) {
+			opts.replaces[i].skipped = fmt.Sprintf("module is replaced by local directory %q in %s", path, source)
+			log.WithModule(r.oldPath).Debugf("skipped: %s", opts.replaces[i].skipped)
+			skipped++
+			continue
+		}
+		if path == r.newPath && len(r.oldPathVersion) > 0 && golang.CompareVersions(version, r.oldPathVersion) < 0 {
+			// the module keeps its version, it is reported as failed instead
+			opts.replaces[i].failure = fmt.Sprintf("conflict: %s use %s which is older than current %s", source, version, r.oldPathVersion)
+			if opts.FailFast {
+				return fmt.Errorf("failed to sync %s: %s", r.oldPath, opts.replaces[i].failure)
+			}
+			conflicts++
+			continue
+		}
+		if path != r.newPath {
+			log.Infof("Module path %q replace target changed from %q to %q", r.oldPath, r.newPath, path)
+		}
+		opts.replaces[i].newPath = path
+		opts.replaces[i].newPathVersion = version
+		opts.replaces[i].setRequire = r.required && len(m.ReplacePath) == 0
+		synced++
+	}
+	log.Infof("Synced %d modules from %s (%d skipped, %d conflicts)", synced, source, skipped, conflicts)
+	return nil
+}
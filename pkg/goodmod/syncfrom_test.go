@@ -0,0 +1,173 @@
+package goodmod
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyncFromLocalGoMod(t *testing.T) {
+	dir, err := ioutil.TempDir("", "syncfrom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	goModPath := filepath.Join(dir, "go.mod")
+	goMod := `module example.com/test
+
+require (
+	github.com/openshift/api v0.0.0-20191001000000-aaaaaaaaaaaa
+	github.com/openshift/build-machinery-go v0.0.0-20191201000000-bbbbbbbbbbbb
+	github.com/openshift/client-go v0.0.0-20191001000000-aaaaaaaaaaaa
+	github.com/openshift/library-go v0.0.0-20191001000000-aaaaaaaaaaaa
+	github.com/openshift/router v0.0.0-20191001000000-aaaaaaaaaaaa
+	k8s.io/api v0.16.0
+	k8s.io/klog v1.0.0
+)
+
+replace github.com/openshift/client-go => github.com/openshift/client-go v0.0.0-20191001000000-aaaaaaaaaaaa
+`
+	if err := ioutil.WriteFile(goModPath, []byte(goMod), 0644); err != nil {
+		t.Fatal(err)
+	}
+	source := filepath.Join(dir, "origin")
+	if err := os.MkdirAll(source, 0755); err != nil {
+		t.Fatal(err)
+	}
+	sourceGoMod := `module github.com/openshift/origin
+
+require (
+	github.com/openshift/api v0.0.0-20191105131421-3d4e2ac1b2ad
+	github.com/openshift/build-machinery-go v0.0.0-20191105131421-3d4e2ac1b2ad
+	github.com/openshift/client-go v0.0.0-20191105131421-3d4e2ac1b2ad
+	github.com/openshift/library-go v0.0.0-20191105131421-3d4e2ac1b2ad
+	k8s.io/api v0.17.0
+	k8s.io/klog v1.0.1
+)
+
+replace github.com/openshift/client-go => github.com/fork/client-go v0.0.0-20191105131421-3d4e2ac1b2ad
+`
+	if err := ioutil.WriteFile(filepath.Join(source, "go.mod"), []byte(sourceGoMod), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the rules of the config file are not used
+	configPath := filepath.Join(dir, "goodmod.yaml")
+	if err := ioutil.WriteFile(configPath, []byte("rules:\n- paths:\n  - github.com/openshift/api\n  branch: master\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	planner := &Planner{
+		Options: Options{
+			ConfigPath: configPath,
+			GoModPath:  goModPath,
+			Paths:      []string{"github.com/openshift/*", "k8s.io/*"},
+			Excludes:   []string{"github.com/openshift/library-go", "k8s.io/klog"},
+			SyncFrom:   source,
+		},
+		IgnoreRules: true,
+	}
+	plan, err := planner.Plan(context.TODO(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]Change{
+		"github.com/openshift/api":       {Directive: "require", NewPath: "github.com/openshift/api", NewVersion: "v0.0.0-20191105131421-3d4e2ac1b2ad"},
+		"github.com/openshift/client-go": {Directive: "replace", NewPath: "github.com/fork/client-go", NewVersion: "v0.0.0-20191105131421-3d4e2ac1b2ad"},
+		"k8s.io/api":                     {Directive: "require", NewPath: "k8s.io/api", NewVersion: "v0.17.0"},
+		// older in source is a conflict and not required by source is skipped, both keep their version
+		"github.com/openshift/build-machinery-go": {Directive: "replace", Failure: "conflict: " + filepath.Join(source, "go.mod") + " use v0.0.0-20191105131421-3d4e2ac1b2ad which is older than current v0.0.0-20191201000000-bbbbbbbbbbbb"},
+		"github.com/openshift/router":             {Directive: "replace", Skipped: "module is not required by " + filepath.Join(source, "go.mod")},
+	}
+	if len(plan.Changes) != len(expected) {
+		t.Errorf("expected %d changes, got %#v", len(expected), plan.Changes)
+	}
+	for _, c := range plan.Changes {
+		e, ok := expected[c.OldPath]
+		if !ok {
+			t.Errorf("unexpected change of %s", c.OldPath)
+			continue
+		}
+		if c.Directive != e.Directive || c.NewVersion != e.NewVersion || (len(e.NewVersion) > 0 && c.NewPath != e.NewPath) {
+			t.Errorf("%s: expected %s %s@%s, got %s %s@%s", c.OldPath, e.Directive, e.NewPath, e.NewVersion, c.Directive, c.NewPath, c.NewVersion)
+		}
+		if c.Failure != e.Failure || c.Skipped != e.Skipped {
+			t.Errorf("%s: expected failure %q and skipped %q, got %q and %q", c.OldPath, e.Failure, e.Skipped, c.Failure, c.Skipped)
+		}
+	}
+	if failed := plan.Failed(); len(failed) != 1 {
+		t.Errorf("expected only the conflict to fail, got %#v", failed)
+	}
+
+	if err := (&Applier{}).Apply(context.TODO(), plan); err != nil {
+		t.Fatal(err)
+	}
+	content, err := ioutil.ReadFile(goModPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		"github.com/openshift/api v0.0.0-20191105131421-3d4e2ac1b2ad\n",
+		"github.com/openshift/library-go v0.0.0-20191001000000-aaaaaaaaaaaa\n",
+		"k8s.io/api v0.17.0\n",
+		"k8s.io/klog v1.0.0\n",
+		"replace github.com/openshift/client-go => github.com/fork/client-go v0.0.0-20191105131421-3d4e2ac1b2ad\n",
+	} {
+		if !strings.Contains(string(content), line) {
+			t.Errorf("expected %q in go.mod, got:\n%s", line, content)
+		}
+	}
+	if strings.Contains(string(content), "github.com/openshift/api =>") {
+		t.Errorf("expected api required without replace, got:\n%s", content)
+	}
+}
+
+func TestConfigSettings(t *testing.T) {
+	dir, err := ioutil.TempDir("", "syncfrom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	configPath := filepath.Join(dir, "goodmod.yaml")
+	content := `githubHosts:
+- host: github.corp.example.com
+resolvers:
+- cache
+- git
+resolverTimeouts:
+  git: 2m
+rules:
+- paths:
+  - github.com/openshift/api
+  branch: master
+`
+	if err := ioutil.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts, err := configSettings(configPath, Options{SyncFrom: "github.com/openshift/origin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.GithubHosts == nil || !opts.GithubHosts.IsGithub("github.corp.example.com/team/api") {
+		t.Errorf("expected GitHub hosts of the config file, got %#v", opts.GithubHosts)
+	}
+	if strings.Join(opts.Resolvers, ",") != "cache,git" || opts.ResolverTimeouts["git"] != 2*time.Minute {
+		t.Errorf("expected resolvers of the config file, got %v (timeouts %v)", opts.Resolvers, opts.ResolverTimeouts)
+	}
+	if len(opts.Branch) > 0 || opts.SyncFrom != "github.com/openshift/origin" {
+		t.Errorf("expected rules of the config file not used, got %#v", opts)
+	}
+
+	// resolvers set by flag take priority
+	opts, err = configSettings(configPath, Options{Resolvers: []string{"proxy"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(opts.Resolvers, ",") != "proxy" {
+		t.Errorf("expected resolvers of the flag, got %v", opts.Resolvers)
+	}
+}
@@ -0,0 +1,358 @@
+package goodmod
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/mfojtik/goodmod/pkg/golang"
+	"github.com/mfojtik/goodmod/pkg/log"
+	"github.com/mfojtik/goodmod/pkg/resolve/types"
+)
+
+// metadataPrefixes are prefixes of files copied to vendor directory from module root and parent directories of vendored
+// packages.
+var metadataPrefixes = []string{"AUTHORS", "CONTRIBUTORS", "COPYLEFT", "COPYING", "COPYRIGHT", "LEGAL", "LICENSE", "NOTICE", "PATENTS"}
+
+func (opts *Options) vendorDir() string {
+	return filepath.Join(filepath.Dir(opts.GoModPath), "vendor")
+}
+
+func (opts *Options) readGoModModules() (map[string]golang.ModuleVersion, []byte, error) {
+	modBytes, err := ioutil.ReadFile(opts.GoModPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	modules, err := golang.RequiredVersions(opts.GoModPath, modBytes)
+	return modules, modBytes, err
+}
+
+// reportVendorMismatches report differences between go.mod and vendor/modules.txt when the module use vendoring.
+func (opts *Options) reportVendorMismatches() error {
+	modulesTxt := filepath.Join(opts.vendorDir(), "modules.txt")
+	vendor, err := golang.ReadVendorModules(modulesTxt)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	modules, _, err := opts.readGoModModules()
+	if err != nil {
+		return err
+	}
+	mismatches := golang.VendorMismatches(modules, vendor)
+	for _, m := range mismatches {
+		log.WithModule(modulesTxt).Errorf("%s", m)
+	}
+	if len(mismatches) > 0 {
+		log.Infof("Vendor directory is not consistent with go.mod, use --vendor or run 'go mod vendor'")
+	}
+	return nil
+}
+
+// updateVendor refresh the vendored packages of modules that changed, instead of re-vendoring all modules.
+// Packages newly imported from changed modules are not added, 'go mod vendor' must be used for that. The modules that
+// were fetched are vendored even when other modules failed, the failed modules are returned in the error and keep their
+// vendored packages.
+func (opts *Options) updateVendor(ctx context.Context) error {
+	modulesTxt := filepath.Join(opts.vendorDir(), "modules.txt")
+	vendor, err := golang.ReadVendorModules(modulesTxt)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_, modBytes, err := opts.readGoModModules()
+	if err != nil {
+		return err
+	}
+	excludeGoMod := false
+	if goVersion := golang.GoVersion(modBytes); len(goVersion) > 0 {
+		excludeGoMod = golang.CompareVersions("v"+goVersion, "v1.17") >= 0
+	}
+
+	failed := []string{}
+	for _, r := range opts.replaces {
+		if len(r.newPathVersion) == 0 || (r.newPath == r.oldTargetPath && r.newPathVersion == r.oldPathVersion) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		vendored := vendor.Module(r.oldPath)
+		if vendored == nil {
+			log.Infof("Module path %q is not vendored", r.oldPath)
+			continue
+		}
+		m, err := opts.fetchModule(ctx, r.newPath, r.newPathVersion)
+		if err != nil {
+			log.WithModule(r.oldPath).Errorf("unable to update vendor: %v", err)
+			failed = append(failed, r.newPath+"@"+r.newPathVersion)
+			continue
+		}
+		if err := vendorModule(opts.vendorDir(), vendored, m, excludeGoMod); err != nil {
+			log.WithModule(r.oldPath).Errorf("unable to update vendor: %v", err)
+			failed = append(failed, r.newPath+"@"+r.newPathVersion)
+			continue
+		}
+		log.Infof("Vendored %d packages of module path %q from %s@%s", len(vendored.Packages), r.oldPath, m.Path, m.Version)
+		if r.setRequire {
+			vendored.Version = r.newPathVersion
+		} else {
+			vendored.ReplacePath, vendored.ReplaceVersion = r.newPath, r.newPathVersion
+		}
+		setAnnotationGoVersion(vendored, golang.GoVersion(m.GoMod))
+	}
+	if err := vendor.Write(modulesTxt); err != nil {
+		return err
+	}
+	if err := opts.reportVendorMismatches(); err != nil {
+		return err
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("unable to update %s for %s", opts.vendorDir(), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// setAnnotationGoVersion update the go version in the module annotation (## explicit; go 1.13) when it is present.
+func setAnnotationGoVersion(m *golang.VendorModule, goVersion string) {
+	if len(goVersion) == 0 {
+		return
+	}
+	for i, a := range m.Annotations {
+		parts := strings.Split(a, "; ")
+		for j := range parts {
+			if strings.HasPrefix(parts[j], "go ") {
+				parts[j] = "go " + goVersion
+			}
+		}
+		m.Annotations[i] = strings.Join(parts, "; ")
+	}
+}
+
+// vendorModule replace the files of all vendored packages of the module with files from new module version. The new
+// files are staged next to the vendor directory first and then renamed into place, so the vendor directory is left
+// unchanged when any file fails to copy or rename.
+func vendorModule(vendorDir string, vendored *golang.VendorModule, m *types.Module, excludeGoMod bool) error {
+	staging, err := ioutil.TempDir(filepath.Dir(vendorDir), ".vendor-")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := os.RemoveAll(staging); err != nil {
+			log.Infof("Unable to remove %q: %v", staging, err)
+		}
+	}()
+	newDir, oldDir := filepath.Join(staging, "new"), filepath.Join(staging, "old")
+	if err := stageModule(newDir, vendored, m, excludeGoMod); err != nil {
+		return err
+	}
+	return replaceVendorFiles(vendorDir, newDir, oldDir, vendored.Packages)
+}
+
+// stageModule copy the files of vendored packages and the license and other metadata files from parent directories of
+// packages up to module root to the staging directory, using the vendor directory layout.
+func stageModule(stagingDir string, vendored *golang.VendorModule, m *types.Module, excludeGoMod bool) error {
+	filesByDir := map[string][]types.ModuleFile{}
+	for _, f := range m.Files {
+		dir := path.Dir(f.Name)
+		if dir == "." {
+			dir = ""
+		}
+		filesByDir[dir] = append(filesByDir[dir], f)
+	}
+	for _, pkg := range vendored.Packages {
+		rel := strings.TrimPrefix(strings.TrimPrefix(pkg, vendored.Path), "/")
+		files, ok := filesByDir[rel]
+		if !ok {
+			return fmt.Errorf("package %q does not exist in %s@%s", pkg, m.Path, m.Version)
+		}
+		pkgDir := filepath.Join(stagingDir, filepath.FromSlash(pkg))
+		if err := os.MkdirAll(pkgDir, 0755); err != nil {
+			return err
+		}
+		for _, f := range files {
+			if excludeGoMod && (path.Base(f.Name) == "go.mod" || path.Base(f.Name) == "go.sum") {
+				continue
+			}
+			if err := copyPackageFile(f, filepath.Join(pkgDir, path.Base(f.Name))); err != nil {
+				return err
+			}
+		}
+		for dir := rel; ; dir = path.Dir(dir) {
+			if dir == "." {
+				dir = ""
+			}
+			for _, f := range filesByDir[dir] {
+				if !isMetadataFile(path.Base(f.Name)) {
+					continue
+				}
+				if err := copyModuleFile(f, filepath.Join(stagingDir, filepath.FromSlash(vendored.Path), filepath.FromSlash(f.Name))); err != nil {
+					return err
+				}
+			}
+			if len(dir) == 0 {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// rename is a file moved by replaceVendorFiles, it is moved back when replacing fails.
+type rename struct {
+	from, to string
+}
+
+// replaceVendorFiles move the files of the packages (but not sub-directories, they are other vendored packages) and
+// the files overwritten by staged files to the backup directory and move the staged files into the vendor directory.
+// When any rename fails, all files are moved back.
+func replaceVendorFiles(vendorDir, stagingDir, backupDir string, packages []string) (err error) {
+	renamed := []rename{}
+	move := func(from, to string) error {
+		if err := os.MkdirAll(filepath.Dir(to), 0755); err != nil {
+			return err
+		}
+		if err := os.Rename(from, to); err != nil {
+			return err
+		}
+		renamed = append(renamed, rename{from: from, to: to})
+		return nil
+	}
+	defer func() {
+		if err == nil {
+			return
+		}
+		for i := len(renamed) - 1; i >= 0; i-- {
+			if restoreErr := os.Rename(renamed[i].to, renamed[i].from); restoreErr != nil {
+				err = fmt.Errorf("%v (unable to restore %q: %v)", err, renamed[i].from, restoreErr)
+			}
+		}
+	}()
+
+	for _, pkg := range packages {
+		entries, err := ioutil.ReadDir(filepath.Join(vendorDir, filepath.FromSlash(pkg)))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if e.IsDir() {
+				continue
+			}
+			name := filepath.Join(filepath.FromSlash(pkg), e.Name())
+			if err := move(filepath.Join(vendorDir, name), filepath.Join(backupDir, name)); err != nil {
+				return err
+			}
+		}
+	}
+	staged := []string{}
+	err = filepath.Walk(stagingDir, func(file string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		name, err := filepath.Rel(stagingDir, file)
+		if err != nil {
+			return err
+		}
+		staged = append(staged, name)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, name := range staged {
+		target := filepath.Join(vendorDir, name)
+		if _, err := os.Lstat(target); err == nil {
+			if err := move(target, filepath.Join(backupDir, name)); err != nil {
+				return err
+			}
+		}
+		if err := move(filepath.Join(stagingDir, name), target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isMetadataFile(name string) bool {
+	for _, prefix := range metadataPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasIgnoreBuildTag return true when the Go file is excluded from build using 'ignore' build tag.
+func hasIgnoreBuildTag(content []byte) bool {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "package ") {
+			return false
+		}
+		if (strings.HasPrefix(line, "// +build") || strings.HasPrefix(line, "//go:build")) && strings.Contains(line, "ignore") {
+			for _, f := range strings.Fields(strings.NewReplacer("//go:build", "", "// +build", "", "!", " ! ", "&&", " ", "||", " ", ",", " ").Replace(line)) {
+				if f == "ignore" {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// copyPackageFile copy the module file to vendor directory, skipping test files and files ignored by build tag.
+func copyPackageFile(f types.ModuleFile, target string) error {
+	if strings.HasSuffix(f.Name, "_test.go") {
+		return nil
+	}
+	if strings.HasSuffix(f.Name, ".go") {
+		r, err := f.Open()
+		if err != nil {
+			return err
+		}
+		content, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			return err
+		}
+		if hasIgnoreBuildTag(content) {
+			return nil
+		}
+	}
+	return copyModuleFile(f, target)
+}
+
+func copyModuleFile(f types.ModuleFile, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	r, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	out, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
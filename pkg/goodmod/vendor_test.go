@@ -0,0 +1,203 @@
+package goodmod
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mfojtik/goodmod/pkg/golang"
+	"github.com/mfojtik/goodmod/pkg/resolve/types"
+)
+
+func moduleFile(name, content string) types.ModuleFile {
+	return types.ModuleFile{Name: name, Open: func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(strings.NewReader(content)), nil
+	}}
+}
+
+func TestVendorModule(t *testing.T) {
+	vendorDir, err := ioutil.TempDir("", "vendor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(vendorDir)
+
+	pkgDir := filepath.Join(vendorDir, "example.com", "mod", "sub")
+	if err := os.MkdirAll(filepath.Join(pkgDir, "nested"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(pkgDir, "removed.go"), []byte("package sub\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &types.Module{
+		Path:    "example.com/mod",
+		Version: "v1.1.0",
+		Files: []types.ModuleFile{
+			moduleFile("LICENSE", "license"),
+			moduleFile("root.go", "package mod\n"),
+			moduleFile("sub/NOTICE", "notice"),
+			moduleFile("sub/b.go", "package sub\n"),
+			moduleFile("sub/b_test.go", "package sub\n"),
+			moduleFile("sub/gen.go", "//go:build ignore\n\npackage main\n"),
+		},
+	}
+	vendored := &golang.VendorModule{Path: "example.com/mod", Version: "v1.0.0", Packages: []string{"example.com/mod/sub"}}
+	if err := vendorModule(vendorDir, vendored, m, true); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range []string{"example.com/mod/LICENSE", "example.com/mod/sub/NOTICE", "example.com/mod/sub/b.go"} {
+		if _, err := os.Stat(filepath.Join(vendorDir, f)); err != nil {
+			t.Errorf("expected %s to be vendored: %v", f, err)
+		}
+	}
+	for _, f := range []string{"example.com/mod/root.go", "example.com/mod/sub/b_test.go", "example.com/mod/sub/gen.go", "example.com/mod/sub/removed.go"} {
+		if _, err := os.Stat(filepath.Join(vendorDir, f)); !os.IsNotExist(err) {
+			t.Errorf("expected %s not to be vendored", f)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(pkgDir, "nested")); err != nil {
+		t.Errorf("expected nested package directory to be kept: %v", err)
+	}
+
+	vendored.Packages = append(vendored.Packages, "example.com/mod/gone")
+	if err := vendorModule(vendorDir, vendored, m, true); err == nil {
+		t.Error("expected error for package missing in new module version")
+	}
+}
+
+func TestVendorModuleFailureKeepsVendor(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vendor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	vendorDir := filepath.Join(dir, "vendor")
+	pkgDir := filepath.Join(vendorDir, "example.com", "mod", "sub")
+	if err := os.MkdirAll(pkgDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(pkgDir, "a.go"), []byte("package sub\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &types.Module{Path: "example.com/mod", Version: "v1.1.0", Files: []types.ModuleFile{moduleFile("sub/b.go", "package sub\n")}}
+	// the first package is staged before the missing package fails the vendoring
+	vendored := &golang.VendorModule{Path: "example.com/mod", Version: "v1.0.0", Packages: []string{"example.com/mod/sub", "example.com/mod/gone"}}
+	if err := vendorModule(vendorDir, vendored, m, true); err == nil {
+		t.Fatal("expected error for package missing in new module version")
+	}
+	if _, err := os.Stat(filepath.Join(pkgDir, "a.go")); err != nil {
+		t.Errorf("expected old file kept: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(pkgDir, "b.go")); !os.IsNotExist(err) {
+		t.Errorf("expected new file not vendored")
+	}
+	if entries, err := ioutil.ReadDir(dir); err != nil || len(entries) != 1 {
+		t.Errorf("expected staging directory removed, got %v (%v)", entries, err)
+	}
+}
+
+func TestSetAnnotationGoVersion(t *testing.T) {
+	m := &golang.VendorModule{Annotations: []string{"explicit; go 1.13"}}
+	setAnnotationGoVersion(m, "1.16")
+	if m.Annotations[0] != "explicit; go 1.16" {
+		t.Errorf("unexpected annotation %q", m.Annotations[0])
+	}
+	m = &golang.VendorModule{Annotations: []string{"explicit"}}
+	setAnnotationGoVersion(m, "1.16")
+	if m.Annotations[0] != "explicit" {
+		t.Errorf("unexpected annotation %q", m.Annotations[0])
+	}
+}
+
+func TestUpdateVendor(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vendor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	src := filepath.Join(dir, "src")
+	repository := filepath.Join(src, "github.com", "openshift", "api")
+	if err := os.MkdirAll(repository, 0755); err != nil {
+		t.Fatal(err)
+	}
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repository
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Skipf("git %s: %v: %s", strings.Join(args, " "), err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "-q")
+	for name, content := range map[string]string{"go.mod": "module github.com/openshift/api\n", "api.go": "package api\n"} {
+		if err := ioutil.WriteFile(filepath.Join(repository, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+	newVersion := "v0.0.0-20191105131421-" + git("rev-parse", "HEAD")[:12]
+	oldVersion := "v0.0.0-20191001000000-aaaaaaaaaaaa"
+
+	// go.mod is already written when vendor is updated
+	goMod := "module example.com/test\n\ngo 1.13\n\nrequire (\n\tgithub.com/openshift/api " + newVersion + "\n\tgithub.com/openshift/client-go " + newVersion + "\n)\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0644); err != nil {
+		t.Fatal(err)
+	}
+	modulesTxt := "# github.com/openshift/api " + oldVersion + "\ngithub.com/openshift/api\n# github.com/openshift/client-go " + oldVersion + "\ngithub.com/openshift/client-go\n"
+	for name, content := range map[string]string{
+		"modules.txt":                              modulesTxt,
+		"github.com/openshift/api/old.go":          "package api\n",
+		"github.com/openshift/client-go/client.go": "package client\n",
+	} {
+		path := filepath.Join(dir, "vendor", name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	opts := &Options{
+		GoModPath:  filepath.Join(dir, "go.mod"),
+		Offline:    true,
+		LocalRepos: []string{src},
+		replaces: []moduleReplace{
+			{oldPath: "github.com/openshift/api", oldTargetPath: "github.com/openshift/api", oldPathVersion: oldVersion, newPath: "github.com/openshift/api", newPathVersion: newVersion, required: true, setRequire: true},
+			// there is no local clone of client-go
+			{oldPath: "github.com/openshift/client-go", oldTargetPath: "github.com/openshift/client-go", oldPathVersion: oldVersion, newPath: "github.com/openshift/client-go", newPathVersion: newVersion, required: true, setRequire: true},
+		},
+	}
+	err = opts.updateVendor(context.TODO())
+	if err == nil || !strings.Contains(err.Error(), "github.com/openshift/client-go@"+newVersion) || strings.Contains(err.Error(), "github.com/openshift/api@") {
+		t.Errorf("expected client-go failure, got %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "vendor", "github.com", "openshift", "api", "api.go")); err != nil {
+		t.Errorf("expected api vendored: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "vendor", "github.com", "openshift", "client-go", "client.go")); err != nil {
+		t.Errorf("expected failed client-go kept: %v", err)
+	}
+	content, err := ioutil.ReadFile(filepath.Join(dir, "vendor", "modules.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"# github.com/openshift/api " + newVersion + "\n", "# github.com/openshift/client-go " + oldVersion + "\n"} {
+		if !strings.Contains(string(content), line) {
+			t.Errorf("expected %q in modules.txt, got:\n%s", line, content)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package goodmod
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+
+	"github.com/mfojtik/goodmod/pkg/golang"
+)
+
+// Targets are the files the changes are written to.
+const (
+	TargetGoMod  = "go.mod"
+	TargetGoWork = "go.work"
+)
+
+func (opts *Options) isWorkspace() bool {
+	return opts.Target == TargetGoWork
+}
+
+// sumPath return the path to checksum file of the target, go.work.sum is used in workspace.
+func (opts *Options) sumPath() string {
+	if opts.isWorkspace() {
+		return filepath.Join(filepath.Dir(opts.GoWorkPath), "go.work.sum")
+	}
+	return filepath.Join(filepath.Dir(opts.GoModPath), "go.sum")
+}
+
+// targetModules return the modules the target file (go.mod or all modules in go.work) require and replace.
+func (opts *Options) targetModules() (map[string]golang.ModuleVersion, error) {
+	if opts.isWorkspace() {
+		work, modules, err := golang.ReadWorkspace(opts.GoWorkPath)
+		if err != nil {
+			return nil, err
+		}
+		return golang.WorkspaceVersions(work, modules), nil
+	}
+	modules, _, err := opts.readGoModModules()
+	return modules, err
+}
+
+// parseWorkspaceModules parse the go.work replace block and modules required by all go.mod files the workspace use and
+// filter out only modules matching the name prefixes specified with this command.
+// All changes are written as go.work replace directives, so required modules are never updated using require.
+func (opts *Options) parseWorkspaceModules() error {
+	work, modules, err := golang.ReadWorkspace(opts.GoWorkPath)
+	if err != nil {
+		return err
+	}
+	opts.goModModules = golang.WorkspaceVersions(work, modules)
+	opts.replaces = []moduleReplace{}
+	for _, r := range work.Replace {
+		if opts.matchPath(r.Old.Path) && !golang.IsDirectoryPath(r.New.Path) {
+			opts.replaces = append(opts.replaces, moduleReplace{newPath: r.New.Path, oldPath: r.Old.Path, oldPathVersion: r.New.Version, oldTargetPath: r.New.Path})
+		}
+	}
+	paths := []string{}
+	for path := range opts.goModModules {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if opts.hasReplacePath(path) || !opts.matchPath(path) {
+			continue
+		}
+		targetPath, targetVersion := opts.goModModules[path].Target()
+		if golang.IsDirectoryPath(targetPath) {
+			logLinked(path, targetPath)
+			continue
+		}
+		opts.replaces = append(opts.replaces, moduleReplace{newPath: targetPath, oldPath: path, oldPathVersion: targetVersion, oldTargetPath: targetPath})
+	}
+	return nil
+}
+
+// applyWorkReplaces write all resolved replaces to the go.work replace block.
+func (opts *Options) applyWorkReplaces() error {
+	workBytes, err := ioutil.ReadFile(opts.GoWorkPath)
+	if err != nil {
+		return err
+	}
+	f, err := golang.ParseWorkFile(opts.GoWorkPath, workBytes, nil)
+	if err != nil {
+		return err
+	}
+	for _, r := range opts.replaces {
+		if len(r.newPathVersion) == 0 {
+			continue
+		}
+		if err := f.AddReplace(r.oldPath, "", r.newPath, r.newPathVersion); err != nil {
+			return fmt.Errorf("%s: %v", opts.GoWorkPath, err)
+		}
+	}
+	f.Cleanup()
+	out, err := f.Format()
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(opts.GoWorkPath, out, 0644)
+}
@@ -0,0 +1,53 @@
+package goodmod
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWorkspaceReplace(t *testing.T) {
+	dir, err := ioutil.TempDir("", "workspace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"go.work":         "go 1.18\n\nuse (\n\t./api\n\t./operator\n)\n\nreplace k8s.io/api => k8s.io/api v0.19.0\n",
+		"api/go.mod":      "module example.com/api\n\nrequire k8s.io/api v0.18.0\n",
+		"operator/go.mod": "module example.com/operator\n\nrequire (\n\texample.com/api v0.1.0\n\tk8s.io/apimachinery v0.18.0\n)\n",
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	opts := &Options{Target: TargetGoWork, GoWorkPath: filepath.Join(dir, "go.work"), Paths: []string{"k8s.io/*"}}
+	if err := opts.parseModules(); err != nil {
+		t.Fatal(err)
+	}
+	if len(opts.replaces) != 2 || opts.replaces[0].oldPath != "k8s.io/api" || opts.replaces[0].oldPathVersion != "v0.19.0" ||
+		opts.replaces[1].oldPath != "k8s.io/apimachinery" || opts.replaces[1].oldPathVersion != "v0.18.0" {
+		t.Fatalf("unexpected replaces: %#v", opts.replaces)
+	}
+	for i := range opts.replaces {
+		opts.replaces[i].newPathVersion = "v0.20.0"
+	}
+	if err := opts.apply(context.TODO(), &Applier{}); err != nil {
+		t.Fatal(err)
+	}
+	out, err := ioutil.ReadFile(opts.GoWorkPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "go 1.18\n\nuse (\n\t./api\n\t./operator\n)\n\nreplace k8s.io/api => k8s.io/api v0.20.0\n\nreplace k8s.io/apimachinery => k8s.io/apimachinery v0.20.0\n"
+	if string(out) != expected {
+		t.Errorf("unexpected go.work:\n%s", out)
+	}
+}
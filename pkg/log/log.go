@@ -0,0 +1,240 @@
+// Package log is the leveled logger shared by all goodmod commands.
+//
+// Messages are written to standard error output, either as text lines prefixed with '#' (so the standard output with
+// commands can still be piped to shell) or as JSON objects, one per line. Errors and warnings are always printed, other
+// levels are enabled by verbosity (-v, -vv, -vvv).
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+type Level int
+
+const (
+	// ErrorLevel include errors and warnings and it is always enabled
+	ErrorLevel Level = iota
+	// InfoLevel include progress of commands (-v)
+	InfoLevel
+	// DebugLevel include progress of individual modules (-vv)
+	DebugLevel
+	// TraceLevel include executed commands and requests (-vvv)
+	TraceLevel
+)
+
+const (
+	TextFormat = "text"
+	JSONFormat = "json"
+)
+
+// Fields are additional key/value pairs attached to the message (eg. module path).
+type Fields map[string]interface{}
+
+// ModuleField is the field with module path messages are about.
+const ModuleField = "module"
+
+type config struct {
+	sync.Mutex
+	out    io.Writer
+	level  Level
+	format string
+	color  bool
+}
+
+// Logger write messages with its fields.
+type Logger struct {
+	fields Fields
+	config *config
+}
+
+var std = &Logger{
+	config: &config{
+		out:    os.Stderr,
+		format: TextFormat,
+		// color.NoColor is set when the output is not a terminal
+		color: len(os.Getenv("NO_COLOR")) == 0 && !color.NoColor,
+	},
+}
+
+// Configure set the level from verbosity (number of -v flags) and the log format.
+func Configure(verbosity int, format string) error {
+	if format != TextFormat && format != JSONFormat {
+		return fmt.Errorf("log format must be %q or %q", TextFormat, JSONFormat)
+	}
+	level := Level(verbosity)
+	if level > TraceLevel {
+		level = TraceLevel
+	}
+	std.config.Lock()
+	defer std.config.Unlock()
+	std.config.level = level
+	std.config.format = format
+	return nil
+}
+
+// SetOutput set the writer messages are written to.
+func SetOutput(w io.Writer) {
+	std.config.Lock()
+	defer std.config.Unlock()
+	std.config.out = w
+}
+
+// SetColor enable or disable colors in text format. Colors are disabled by default when NO_COLOR environment variable is
+// set or when the output is not a terminal.
+func SetColor(enabled bool) {
+	std.config.Lock()
+	defer std.config.Unlock()
+	std.config.color = enabled
+}
+
+// V return true when the level is enabled.
+func V(level Level) bool {
+	std.config.Lock()
+	defer std.config.Unlock()
+	return level <= std.config.level
+}
+
+// WithModule return logger that attach the module path to all messages.
+func WithModule(modulePath string) *Logger {
+	return std.WithField(ModuleField, modulePath)
+}
+
+// WithField return logger that attach the field to all messages.
+func WithField(key string, value interface{}) *Logger {
+	return std.WithField(key, value)
+}
+
+// Errorf print error, errors are always printed.
+func Errorf(format string, args ...interface{}) {
+	std.log(ErrorLevel, "error", format, args...)
+}
+
+// Warningf print warning, warnings are always printed.
+func Warningf(format string, args ...interface{}) {
+	std.log(ErrorLevel, "warning", format, args...)
+}
+
+// Infof print progress of command when verbosity is at least 1.
+func Infof(format string, args ...interface{}) {
+	std.log(InfoLevel, "info", format, args...)
+}
+
+// Debugf print progress of individual modules when verbosity is at least 2.
+func Debugf(format string, args ...interface{}) {
+	std.log(DebugLevel, "debug", format, args...)
+}
+
+// Tracef print executed commands and requests when verbosity is at least 3.
+func Tracef(format string, args ...interface{}) {
+	std.log(TraceLevel, "trace", format, args...)
+}
+
+// WithField return logger that attach the field to all messages in addition to fields of this logger.
+func (l *Logger) WithField(key string, value interface{}) *Logger {
+	fields := Fields{}
+	for k, v := range l.fields {
+		fields[k] = v
+	}
+	fields[key] = value
+	return &Logger{fields: fields, config: l.config}
+}
+
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.log(ErrorLevel, "error", format, args...)
+}
+
+func (l *Logger) Warningf(format string, args ...interface{}) {
+	l.log(ErrorLevel, "warning", format, args...)
+}
+
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.log(InfoLevel, "info", format, args...)
+}
+
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.log(DebugLevel, "debug", format, args...)
+}
+
+func (l *Logger) Tracef(format string, args ...interface{}) {
+	l.log(TraceLevel, "trace", format, args...)
+}
+
+func (l *Logger) log(level Level, levelName string, format string, args ...interface{}) {
+	l.config.Lock()
+	defer l.config.Unlock()
+	if level > l.config.level {
+		return
+	}
+	message := fmt.Sprintf(format, args...)
+	var line string
+	if l.config.format == JSONFormat {
+		line = l.formatJSON(levelName, message)
+	} else {
+		line = l.formatText(levelName, message)
+	}
+	// there is nothing better to do when writing to standard error output fails
+	_, _ = io.WriteString(l.config.out, line+"\n")
+}
+
+// formatText format the message as comment, errors and warnings are prefixed with module path and highlighted.
+func (l *Logger) formatText(levelName string, message string) string {
+	var extra []string
+	for _, k := range l.sortedKeys() {
+		if k != ModuleField {
+			extra = append(extra, fmt.Sprintf("%s=%v", k, l.fields[k]))
+		}
+	}
+	if len(extra) > 0 {
+		message += " (" + strings.Join(extra, ", ") + ")"
+	}
+	if levelName != "error" && levelName != "warning" {
+		return "# " + message
+	}
+	if modulePath, ok := l.fields[ModuleField]; ok {
+		message = fmt.Sprintf("%v: %s", modulePath, message)
+	}
+	message = strings.ToUpper(levelName) + ": " + message
+	if l.config.color {
+		attribute := color.FgHiRed
+		if levelName == "warning" {
+			attribute = color.FgHiYellow
+		}
+		c := color.New(attribute, color.Bold)
+		c.EnableColor()
+		message = c.Sprint(message)
+	}
+	return "### " + message
+}
+
+func (l *Logger) formatJSON(levelName string, message string) string {
+	entry := map[string]interface{}{}
+	for k, v := range l.fields {
+		entry[k] = v
+	}
+	entry["time"] = time.Now().UTC().Format(time.RFC3339)
+	entry["level"] = levelName
+	entry["msg"] = message
+	out, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Sprintf(`{"level":"error","msg":%q}`, err.Error())
+	}
+	return string(out)
+}
+
+func (l *Logger) sortedKeys() []string {
+	keys := make([]string, 0, len(l.fields))
+	for k := range l.fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,61 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func setup(t *testing.T, verbosity int, format string) *bytes.Buffer {
+	out := &bytes.Buffer{}
+	SetOutput(out)
+	SetColor(false)
+	if err := Configure(verbosity, format); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestTextFormat(t *testing.T) {
+	out := setup(t, 1, TextFormat)
+	WithModule("k8s.io/api").Errorf("unable to get commit")
+	WithModule("k8s.io/api").WithField("resolver", "git").Warningf("rate limited")
+	Infof("Loaded %d go.mod rules", 2)
+	Debugf("Resolving module path %q ...", "k8s.io/api")
+
+	expected := `### ERROR: k8s.io/api: unable to get commit
+### WARNING: k8s.io/api: rate limited (resolver=git)
+# Loaded 2 go.mod rules
+`
+	if out.String() != expected {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+}
+
+func TestJSONFormat(t *testing.T) {
+	out := setup(t, 3, JSONFormat)
+	WithModule("k8s.io/api").Tracef("Running %q ...", "go mod edit")
+
+	entry := map[string]string{}
+	if err := json.Unmarshal(out.Bytes(), &entry); err != nil {
+		t.Fatalf("unable to parse %q: %v", out.String(), err)
+	}
+	if entry["level"] != "trace" || entry["module"] != "k8s.io/api" || entry["msg"] != `Running "go mod edit" ...` || len(entry["time"]) == 0 {
+		t.Errorf("unexpected entry: %#v", entry)
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	setup(t, 5, TextFormat)
+	if !V(TraceLevel) {
+		t.Error("expected verbosity over trace level to enable trace")
+	}
+	setup(t, 0, TextFormat)
+	if V(InfoLevel) || !V(ErrorLevel) {
+		t.Error("expected only errors to be enabled by default")
+	}
+	if err := Configure(0, "xml"); err == nil || !strings.Contains(err.Error(), "log format") {
+		t.Errorf("expected invalid format error, got %v", err)
+	}
+}
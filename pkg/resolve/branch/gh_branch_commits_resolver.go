@@ -2,23 +2,23 @@ package branch
 
 import (
 	"context"
-	"net/http"
-
-	"github.com/google/go-github/v28/github"
 
 	"github.com/mfojtik/goodmod/pkg/resolve"
 )
 
 type GithubBranchCommitsLister struct {
-	oauthClient *http.Client
+	hosts *resolve.GithubHosts
 }
 
-func NewGithubBranchCommitsLister(oauthClient *http.Client) *GithubBranchCommitsLister {
-	return &GithubBranchCommitsLister{oauthClient: oauthClient}
+func NewGithubBranchCommitsLister(hosts *resolve.GithubHosts) *GithubBranchCommitsLister {
+	return &GithubBranchCommitsLister{hosts: hosts}
 }
 
 func (g *GithubBranchCommitsLister) List(ctx context.Context, modulePath string, startingCommit string, branchName string) (int, error) {
-	client := github.NewClient(g.oauthClient)
+	client, err := g.hosts.APIClient(modulePath)
+	if err != nil {
+		return 0, err
+	}
 	owner, repo := resolve.GetGithubOwnerAndRepo(resolve.RepositoryModulePath(modulePath))
 	comparison, _, err := client.Repositories.CompareCommits(ctx, owner, repo, startingCommit, branchName)
 	if err != nil {
@@ -2,24 +2,24 @@ package branch
 
 import (
 	"context"
-	"net/http"
-
-	"github.com/google/go-github/v28/github"
 
 	"github.com/mfojtik/goodmod/pkg/resolve"
 	"github.com/mfojtik/goodmod/pkg/resolve/types"
 )
 
 type GithubBranchResolver struct {
-	oauthClient *http.Client
+	hosts *resolve.GithubHosts
 }
 
-func NewGithubBranchResolver(oauthClient *http.Client) resolve.ModulerResolver {
-	return &GithubBranchResolver{oauthClient: oauthClient}
+func NewGithubBranchResolver(hosts *resolve.GithubHosts) resolve.ModulerResolver {
+	return &GithubBranchResolver{hosts: hosts}
 }
 
 func (g *GithubBranchResolver) Resolve(ctx context.Context, modulePath string, name string) (*types.Commit, error) {
-	client := github.NewClient(g.oauthClient)
+	client, err := g.hosts.APIClient(modulePath)
+	if err != nil {
+		return nil, err
+	}
 	owner, repo := resolve.GetGithubOwnerAndRepo(resolve.RepositoryModulePath(modulePath))
 	branch, _, err := client.Repositories.GetBranch(ctx, owner, repo, name)
 	if err != nil {
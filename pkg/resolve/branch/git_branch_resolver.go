@@ -4,21 +4,20 @@ import (
 	"context"
 	"fmt"
 
-	"gopkg.in/src-d/go-git.v4"
 	"gopkg.in/src-d/go-git.v4/plumbing"
-	"gopkg.in/src-d/go-git.v4/storage/memory"
 
 	"github.com/mfojtik/goodmod/pkg/resolve"
 	"github.com/mfojtik/goodmod/pkg/resolve/types"
 )
 
 type GitBranchResolver struct {
+	auth *resolve.GitAuth
 }
 
 func (g *GitBranchResolver) Resolve(ctx context.Context, modulePath string, name string) (*types.Commit, error) {
-	repository, err := git.Clone(memory.NewStorage(), nil, &git.CloneOptions{URL: resolve.RepositoryModulePath(modulePath)})
+	repository, err := resolve.Clone(ctx, modulePath, g.auth)
 	if err != nil {
-		return nil, fmt.Errorf("failed to clone githelper repository %s: %v", resolve.RepositoryModulePath(modulePath), err)
+		return nil, err
 	}
 	ref, err := repository.Storer.Reference(plumbing.NewBranchReferenceName(name))
 	if err != nil {
@@ -35,6 +34,6 @@ func (g *GitBranchResolver) Resolve(ctx context.Context, modulePath string, name
 	}, nil
 }
 
-func NewGitBranchResolver() resolve.ModulerResolver {
-	return &GitBranchResolver{}
+func NewGitBranchResolver(auth *resolve.GitAuth) resolve.ModulerResolver {
+	return &GitBranchResolver{auth: auth}
 }
@@ -0,0 +1,33 @@
+package branch
+
+import (
+	"context"
+
+	"github.com/mfojtik/goodmod/pkg/resolve"
+	"github.com/mfojtik/goodmod/pkg/resolve/types"
+)
+
+type LocalBranchResolver struct {
+	dirs []string
+}
+
+// NewLocalBranchResolver return resolver that find the branch in the local clone of the module repository. Remote-tracking
+// branches (eg. 'origin/master') are used when the clone has no local branch with the name.
+func NewLocalBranchResolver(dirs []string) resolve.ModulerResolver {
+	return &LocalBranchResolver{dirs: dirs}
+}
+
+func (l *LocalBranchResolver) Resolve(ctx context.Context, modulePath string, name string) (*types.Commit, error) {
+	repository, err := resolve.OpenLocal(l.dirs, modulePath)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := resolve.LocalBranch(repository, name)
+	if err != nil {
+		return nil, err
+	}
+	return &types.Commit{
+		SHA:       commit.Hash.String(),
+		Timestamp: commit.Committer.When,
+	}, nil
+}
@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/mfojtik/goodmod/pkg/golang"
+	"github.com/mfojtik/goodmod/pkg/resolve"
+	"github.com/mfojtik/goodmod/pkg/resolve/types"
+)
+
+// CacheResolver resolve tags and commits from the commits stored by the resolvers following it in the chain. Branches
+// are never cached as their head moves.
+type CacheResolver struct {
+	dir  string
+	kind resolve.RefKind
+}
+
+// DefaultDir return the directory commits are cached in (eg. '~/.cache/goodmod').
+func DefaultDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "goodmod")
+}
+
+// NewCacheResolver return resolver for the ref kind that store commits in the directory.
+func NewCacheResolver(dir string, kind resolve.RefKind) *CacheResolver {
+	return &CacheResolver{dir: dir, kind: kind}
+}
+
+func (c *CacheResolver) path(modulePath string, name string) (string, error) {
+	escapedPath, err := golang.EscapePath(modulePath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(c.dir, "commits", filepath.FromSlash(escapedPath), string(c.kind), url.PathEscape(name)+".json"), nil
+}
+
+func (c *CacheResolver) Resolve(ctx context.Context, modulePath string, name string) (*types.Commit, error) {
+	if c.kind == resolve.BranchRef {
+		return nil, fmt.Errorf("branches are not cached")
+	}
+	path, err := c.path(modulePath, name)
+	if err != nil {
+		return nil, err
+	}
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%s %q is not cached", c.kind, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	commit := &types.Commit{}
+	if err := json.Unmarshal(content, commit); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %v", path, err)
+	}
+	return commit, nil
+}
+
+// Store write the commit to cache, the file is renamed into place so concurrent readers never see partial content.
+func (c *CacheResolver) Store(modulePath string, name string, commit *types.Commit) error {
+	if c.kind == resolve.BranchRef {
+		return nil
+	}
+	path, err := c.path(modulePath, name)
+	if err != nil {
+		return err
+	}
+	content, err := json.Marshal(commit)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".commit")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mfojtik/goodmod/pkg/resolve"
+	"github.com/mfojtik/goodmod/pkg/resolve/types"
+)
+
+func TestCacheResolver(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	commit := &types.Commit{SHA: "35e52d86657a9a6a4ad19d1432e3a8a6a51af3e3", Timestamp: time.Date(2019, 10, 16, 11, 4, 8, 0, time.UTC)}
+
+	for _, kind := range []resolve.RefKind{resolve.TagRef, resolve.BranchRef} {
+		r := NewCacheResolver(dir, kind)
+		if _, err := r.Resolve(context.TODO(), "k8s.io/api", "release/1.16"); err == nil {
+			t.Errorf("%s: expected error before the commit is stored", kind)
+		}
+		if err := r.Store("k8s.io/api", "release/1.16", commit); err != nil {
+			t.Fatal(err)
+		}
+		c, err := r.Resolve(context.TODO(), "k8s.io/api", "release/1.16")
+		if kind == resolve.BranchRef {
+			if err == nil {
+				t.Errorf("expected branches not to be cached, got %s", c.String())
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.String() != commit.String() {
+			t.Errorf("expected %s, got %s", commit.String(), c.String())
+		}
+	}
+}
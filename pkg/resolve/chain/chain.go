@@ -0,0 +1,148 @@
+package chain
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mfojtik/goodmod/pkg/resolve"
+	"github.com/mfojtik/goodmod/pkg/resolve/branch"
+	"github.com/mfojtik/goodmod/pkg/resolve/cache"
+	"github.com/mfojtik/goodmod/pkg/resolve/commit"
+	"github.com/mfojtik/goodmod/pkg/resolve/external"
+	"github.com/mfojtik/goodmod/pkg/resolve/proxy"
+	"github.com/mfojtik/goodmod/pkg/resolve/tag"
+)
+
+// Names are the resolvers that can be used in the chain.
+var Names = []string{resolve.CacheKind, resolve.LocalKind, resolve.ProxyKind, resolve.GithubKind, resolve.GitKind, resolve.ExecKind}
+
+// DefaultResolvers is the chain used when no chain is configured, GitHub API with fallback to git clone.
+var DefaultResolvers = []string{resolve.GithubKind, resolve.GitKind}
+
+// OfflineResolvers is the chain used when no chain is configured and the network must not be used.
+var OfflineResolvers = []string{resolve.CacheKind, resolve.LocalKind}
+
+// networkResolvers are the resolvers that always use the network.
+var networkResolvers = []string{resolve.ProxyKind, resolve.GithubKind, resolve.GitKind}
+
+// Options configure the resolvers of the chain.
+type Options struct {
+	// Github route requests of GitHub resolvers to github.com or the GitHub Enterprise Server hosting the module
+	Github *resolve.GithubHosts
+	// GitAuth select the authentication of git clones, clones are anonymous when nil
+	GitAuth *resolve.GitAuth
+	// ExecCommand is the command called by the exec resolver
+	ExecCommand string
+	// CacheDir is the directory the cache resolver store commits in, cache.DefaultDir() is used when empty
+	CacheDir string
+	// LocalRepos are the directories the local resolver search clones in, resolve.DefaultLocalRepos() are used when empty
+	LocalRepos []string
+}
+
+// Validate return error when the chain include unknown or duplicate resolvers.
+func Validate(names []string) error {
+	seen := map[string]bool{}
+	for _, name := range names {
+		known := false
+		for _, n := range Names {
+			if name == n {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown resolver %q, must be one of: %s", name, strings.Join(Names, ", "))
+		}
+		if seen[name] {
+			return fmt.Errorf("resolver %q is listed more than once", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// ValidateOffline return error when the chain include resolvers that use the network. The exec resolver is allowed, the
+// command is responsible for not using the network.
+func ValidateOffline(names []string) error {
+	for _, name := range names {
+		for _, n := range networkResolvers {
+			if name == n {
+				return fmt.Errorf("resolver %q requires network and cannot be used offline", name)
+			}
+		}
+	}
+	return nil
+}
+
+// New return the resolvers of the chain for the ref kind, in the order they are called. When the names are empty,
+// DefaultResolvers are used.
+func New(names []string, kind resolve.RefKind, o Options) ([]resolve.ModulerResolver, error) {
+	if len(names) == 0 {
+		names = DefaultResolvers
+	}
+	if err := Validate(names); err != nil {
+		return nil, err
+	}
+	resolvers := []resolve.ModulerResolver{}
+	for _, name := range names {
+		switch name {
+		case resolve.CacheKind:
+			dir := o.CacheDir
+			if len(dir) == 0 {
+				dir = cache.DefaultDir()
+			}
+			resolvers = append(resolvers, cache.NewCacheResolver(dir, kind))
+		case resolve.LocalKind:
+			dirs := o.LocalRepos
+			if len(dirs) == 0 {
+				dirs = resolve.DefaultLocalRepos()
+			}
+			resolvers = append(resolvers, localResolver(kind, dirs))
+		case resolve.ProxyKind:
+			resolvers = append(resolvers, proxy.NewProxyResolver())
+		case resolve.GithubKind:
+			resolvers = append(resolvers, githubResolver(kind, o.Github))
+		case resolve.GitKind:
+			resolvers = append(resolvers, gitResolver(kind, o.GitAuth))
+		case resolve.ExecKind:
+			if len(strings.TrimSpace(o.ExecCommand)) == 0 {
+				return nil, fmt.Errorf("exec resolver requires the command to be set")
+			}
+			resolvers = append(resolvers, external.NewExecResolver(o.ExecCommand, kind))
+		}
+	}
+	return resolvers, nil
+}
+
+func githubResolver(kind resolve.RefKind, hosts *resolve.GithubHosts) resolve.ModulerResolver {
+	switch kind {
+	case resolve.BranchRef:
+		return branch.NewGithubBranchResolver(hosts)
+	case resolve.TagRef:
+		return tag.NewGithubTagResolver(hosts)
+	default:
+		return commit.NewGithubCommitResolver(hosts)
+	}
+}
+
+func gitResolver(kind resolve.RefKind, auth *resolve.GitAuth) resolve.ModulerResolver {
+	switch kind {
+	case resolve.BranchRef:
+		return branch.NewGitBranchResolver(auth)
+	case resolve.TagRef:
+		return tag.NewGitTagResolver(auth)
+	default:
+		return commit.NewGitCommitResolver(auth)
+	}
+}
+
+func localResolver(kind resolve.RefKind, dirs []string) resolve.ModulerResolver {
+	switch kind {
+	case resolve.BranchRef:
+		return branch.NewLocalBranchResolver(dirs)
+	case resolve.TagRef:
+		return tag.NewLocalTagResolver(dirs)
+	default:
+		return commit.NewLocalCommitResolver(dirs)
+	}
+}
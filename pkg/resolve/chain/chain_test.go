@@ -0,0 +1,55 @@
+package chain
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mfojtik/goodmod/pkg/resolve"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		names    []string
+		kind     resolve.RefKind
+		expected []string
+		err      bool
+	}{
+		{kind: resolve.BranchRef, expected: []string{"*branch.GithubBranchResolver", "*branch.GitBranchResolver"}},
+		{names: []string{"cache", "proxy", "git"}, kind: resolve.TagRef, expected: []string{"*cache.CacheResolver", "*proxy.ProxyResolver", "*tag.GitTagResolver"}},
+		{names: []string{"exec", "github"}, kind: resolve.CommitRef, expected: []string{"*external.ExecResolver", "*commit.GithubCommitResolver"}},
+		{names: []string{"cache", "local"}, kind: resolve.BranchRef, expected: []string{"*cache.CacheResolver", "*branch.LocalBranchResolver"}},
+		{names: []string{"git", "svn"}, kind: resolve.TagRef, err: true},
+		{names: []string{"git", "git"}, kind: resolve.TagRef, err: true},
+	}
+	for _, test := range tests {
+		resolvers, err := New(test.names, test.kind, Options{ExecCommand: "resolve-ref"})
+		if (err != nil) != test.err {
+			t.Errorf("%v: unexpected error: %v", test.names, err)
+			continue
+		}
+		types := []string{}
+		for _, r := range resolvers {
+			types = append(types, fmt.Sprintf("%T", r))
+		}
+		if fmt.Sprint(types) != fmt.Sprint(test.expected) {
+			t.Errorf("%v: expected %v, got %v", test.names, test.expected, types)
+		}
+	}
+	if _, err := New([]string{"exec"}, resolve.TagRef, Options{}); err == nil {
+		t.Error("expected error for exec resolver without command")
+	}
+}
+
+func TestValidateOffline(t *testing.T) {
+	if err := ValidateOffline(OfflineResolvers); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := ValidateOffline([]string{"local", "exec"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	for _, name := range []string{"proxy", "github", "git"} {
+		if err := ValidateOffline([]string{"local", name}); err == nil {
+			t.Errorf("expected %s resolver to be rejected", name)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package resolve
+
+import (
+	"context"
+	"fmt"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/storage/memory"
+)
+
+// Clone clone the module repository into memory using the authentication selected for the module (anonymous when auth
+// is nil). The clone is aborted when the context is canceled, including the references discovery which go-git does not
+// cancel itself.
+func Clone(ctx context.Context, modulePath string, auth *GitAuth) (*git.Repository, error) {
+	url, method, err := auth.Endpoint(ctx, modulePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone githelper repository %s: %v", url, err)
+	}
+	type result struct {
+		repository *git.Repository
+		err        error
+	}
+	done := make(chan result, 1)
+	go func() {
+		repository, err := git.CloneContext(ctx, memory.NewStorage(), nil, &git.CloneOptions{URL: url, Auth: method})
+		done <- result{repository: repository, err: err}
+	}()
+	select {
+	case r := <-done:
+		if r.err != nil {
+			return nil, fmt.Errorf("failed to clone githelper repository %s: %v", url, r.err)
+		}
+		return r.repository, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("failed to clone githelper repository %s: %v", url, ctx.Err())
+	}
+}
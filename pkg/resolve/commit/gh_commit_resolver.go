@@ -2,31 +2,32 @@ package commit
 
 import (
 	"context"
-	"net/http"
-
-	"github.com/google/go-github/v28/github"
 
 	"github.com/mfojtik/goodmod/pkg/resolve"
 	"github.com/mfojtik/goodmod/pkg/resolve/types"
 )
 
 type GithubCommitResolver struct {
-	oauthClient *http.Client
+	hosts *resolve.GithubHosts
 }
 
-func NewGithubCommitResolver(oauthClient *http.Client) resolve.ModulerResolver {
-	return &GithubCommitResolver{oauthClient: oauthClient}
+func NewGithubCommitResolver(hosts *resolve.GithubHosts) resolve.ModulerResolver {
+	return &GithubCommitResolver{hosts: hosts}
 }
 
 func (g *GithubCommitResolver) Resolve(ctx context.Context, modulePath string, name string) (*types.Commit, error) {
-	client := github.NewClient(g.oauthClient)
+	client, err := g.hosts.APIClient(modulePath)
+	if err != nil {
+		return nil, err
+	}
 	owner, repo := resolve.GetGithubOwnerAndRepo(resolve.RepositoryModulePath(modulePath))
-	commit, _, err := client.Git.GetCommit(ctx, owner, repo, name)
+	// repository commits API accept abbreviated SHA (eg. from pseudo-version)
+	commit, _, err := client.Repositories.GetCommit(ctx, owner, repo, name)
 	if err != nil {
 		return nil, err
 	}
 	return &types.Commit{
 		SHA:       commit.GetSHA(),
-		Timestamp: commit.GetCommitter().GetDate(),
+		Timestamp: commit.GetCommit().GetCommitter().GetDate(),
 	}, nil
 }
@@ -2,23 +2,21 @@ package commit
 
 import (
 	"context"
-	"fmt"
 
-	"gopkg.in/src-d/go-git.v4"
 	"gopkg.in/src-d/go-git.v4/plumbing"
-	"gopkg.in/src-d/go-git.v4/storage/memory"
 
 	"github.com/mfojtik/goodmod/pkg/resolve"
 	"github.com/mfojtik/goodmod/pkg/resolve/types"
 )
 
 type GitCommitResolver struct {
+	auth *resolve.GitAuth
 }
 
 func (g *GitCommitResolver) Resolve(ctx context.Context, modulePath string, name string) (*types.Commit, error) {
-	repository, err := git.Clone(memory.NewStorage(), nil, &git.CloneOptions{URL: resolve.RepositoryModulePath(modulePath)})
+	repository, err := resolve.Clone(ctx, modulePath, g.auth)
 	if err != nil {
-		return nil, fmt.Errorf("failed to clone githelper repository %s: %v", resolve.RepositoryModulePath(modulePath), err)
+		return nil, err
 	}
 	commit, err := repository.CommitObject(plumbing.NewHash(name))
 	if err != nil {
@@ -30,6 +28,6 @@ func (g *GitCommitResolver) Resolve(ctx context.Context, modulePath string, name
 	}, nil
 }
 
-func NewGitCommitResolver() resolve.ModulerResolver {
-	return &GitCommitResolver{}
+func NewGitCommitResolver(auth *resolve.GitAuth) resolve.ModulerResolver {
+	return &GitCommitResolver{auth: auth}
 }
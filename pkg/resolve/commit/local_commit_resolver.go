@@ -0,0 +1,33 @@
+package commit
+
+import (
+	"context"
+
+	"github.com/mfojtik/goodmod/pkg/resolve"
+	"github.com/mfojtik/goodmod/pkg/resolve/types"
+)
+
+type LocalCommitResolver struct {
+	dirs []string
+}
+
+// NewLocalCommitResolver return resolver that find the commit (full or abbreviated SHA) in the local clone of the module
+// repository.
+func NewLocalCommitResolver(dirs []string) resolve.ModulerResolver {
+	return &LocalCommitResolver{dirs: dirs}
+}
+
+func (l *LocalCommitResolver) Resolve(ctx context.Context, modulePath string, name string) (*types.Commit, error) {
+	repository, err := resolve.OpenLocal(l.dirs, modulePath)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := resolve.LocalCommit(repository, name)
+	if err != nil {
+		return nil, err
+	}
+	return &types.Commit{
+		SHA:       commit.Hash.String(),
+		Timestamp: commit.Committer.When,
+	}, nil
+}
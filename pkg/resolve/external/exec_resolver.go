@@ -0,0 +1,50 @@
+package external
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/mfojtik/goodmod/pkg/resolve"
+	"github.com/mfojtik/goodmod/pkg/resolve/types"
+)
+
+// ExecResolver resolve refs by calling an external program with the module path, the ref kind ('branch', 'tag' or
+// 'commit') and the ref name as the last arguments. The program must print the commit as JSON to standard output
+// (eg. '{"sha": "c07a134afb42...", "timestamp": "2019-10-16T11:51:29Z"}').
+type ExecResolver struct {
+	command []string
+	kind    resolve.RefKind
+}
+
+// NewExecResolver return resolver for the ref kind calling the command (eg. '/usr/local/bin/resolve-ref --host=git.corp').
+func NewExecResolver(command string, kind resolve.RefKind) resolve.ModulerResolver {
+	return &ExecResolver{command: strings.Fields(command), kind: kind}
+}
+
+func (e *ExecResolver) Resolve(ctx context.Context, modulePath string, name string) (*types.Commit, error) {
+	if len(e.command) == 0 {
+		return nil, fmt.Errorf("exec resolver command is not set")
+	}
+	args := append(append([]string{}, e.command[1:]...), modulePath, string(e.kind), name)
+	cmd := exec.CommandContext(ctx, e.command[0], args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("%s failed: %v: %s", e.command[0], err, strings.TrimSpace(stderr.String()))
+	}
+	commit := &types.Commit{}
+	if err := json.Unmarshal(stdout.Bytes(), commit); err != nil {
+		return nil, fmt.Errorf("%s returned invalid commit: %v", e.command[0], err)
+	}
+	if len(commit.SHA) < 12 || commit.Timestamp.IsZero() {
+		return nil, fmt.Errorf("%s returned commit without SHA or timestamp: %s", e.command[0], strings.TrimSpace(stdout.String()))
+	}
+	return commit, nil
+}
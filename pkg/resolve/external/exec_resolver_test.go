@@ -0,0 +1,50 @@
+package external
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mfojtik/goodmod/pkg/resolve"
+)
+
+const testResolver = `#!/bin/sh
+# args: [--fail] <module path> <ref kind> <ref name>
+if [ "$1" = "--fail" ]; then
+  echo "unknown host" >&2
+  exit 1
+fi
+if [ "$1/$2/$3" = "git.corp/team/lib/tag/v1.0.0" ]; then
+  echo '{"sha": "c07a134afb4217bd3dc3cd89a6e5eb5b1a2c4b6e", "timestamp": "2019-10-16T11:51:29Z"}'
+  exit 0
+fi
+echo '{}'
+`
+
+func TestExecResolver(t *testing.T) {
+	dir, err := ioutil.TempDir("", "exec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	script := filepath.Join(dir, "resolve-ref")
+	if err := ioutil.WriteFile(script, []byte(testResolver), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := NewExecResolver(script, resolve.TagRef).Resolve(context.TODO(), "git.corp/team/lib", "v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.String() != "v0.0.0-20191016115129-c07a134afb42" {
+		t.Errorf("unexpected commit %s", c.String())
+	}
+	if _, err := NewExecResolver(script, resolve.BranchRef).Resolve(context.TODO(), "git.corp/team/lib", "master"); err == nil {
+		t.Error("expected error for empty commit")
+	}
+	if _, err := NewExecResolver(script+" --fail", resolve.TagRef).Resolve(context.TODO(), "git.corp/team/lib", "v1.0.0"); err == nil {
+		t.Error("expected error when the command fails")
+	}
+}
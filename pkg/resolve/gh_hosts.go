@@ -0,0 +1,87 @@
+package resolve
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/google/go-github/v28/github"
+
+	"github.com/mfojtik/goodmod/pkg/config"
+)
+
+// GithubHosts route GitHub API requests of modules to github.com or to the GitHub Enterprise Server hosting them. The
+// zero value (and nil) only know github.com.
+type GithubHosts struct {
+	// Client is the HTTP client for github.com API, http.DefaultClient is used when nil
+	Client *http.Client
+
+	enterprise map[string]*enterpriseHost
+}
+
+type enterpriseHost struct {
+	apiURL    string
+	uploadURL string
+	client    *http.Client
+}
+
+// NewGithubHosts return the hosts using the client for github.com and a client authenticated with the token from its
+// environment variable for every GitHub Enterprise Server host.
+func NewGithubHosts(client *http.Client, hosts []config.GithubHost, retries int) (*GithubHosts, error) {
+	g := &GithubHosts{Client: client, enterprise: map[string]*enterpriseHost{}}
+	for _, h := range hosts {
+		if len(h.Host) == 0 || strings.ContainsAny(h.Host, "/:") {
+			return nil, fmt.Errorf("GitHub host %q must be a host name (eg. 'github.corp.example.com')", h.Host)
+		}
+		if h.Host == "github.com" {
+			return nil, fmt.Errorf("github.com cannot be configured as GitHub Enterprise Server host")
+		}
+		apiURL, uploadURL := h.APIURL, h.UploadURL
+		if len(apiURL) == 0 {
+			apiURL = "https://" + h.Host + "/api/v3/"
+		}
+		if len(uploadURL) == 0 {
+			uploadURL = "https://" + h.Host + "/api/uploads/"
+		}
+		for _, u := range []string{apiURL, uploadURL} {
+			if parsed, err := url.Parse(u); err != nil || len(parsed.Scheme) == 0 || len(parsed.Host) == 0 {
+				return nil, fmt.Errorf("GitHub host %q has invalid API URL %q", h.Host, u)
+			}
+		}
+		g.enterprise[h.Host] = &enterpriseHost{
+			apiURL:    apiURL,
+			uploadURL: uploadURL,
+			client:    NewGithubClient(os.Getenv(h.TokenEnv), retries),
+		}
+	}
+	return g, nil
+}
+
+// IsGithub return true when the module repository is hosted on github.com or one of the GitHub Enterprise Server hosts.
+func (g *GithubHosts) IsGithub(modulePath string) bool {
+	if IsGithubModule(modulePath) {
+		return true
+	}
+	if g == nil {
+		return false
+	}
+	_, ok := g.enterprise[RepositoryHost(modulePath)]
+	return ok
+}
+
+// APIClient return the GitHub API client for the host of the module repository.
+func (g *GithubHosts) APIClient(modulePath string) (*github.Client, error) {
+	host := RepositoryHost(modulePath)
+	if g == nil {
+		g = &GithubHosts{}
+	}
+	if h, ok := g.enterprise[host]; ok {
+		return github.NewEnterpriseClient(h.apiURL, h.uploadURL, h.client)
+	}
+	if host != "github.com" {
+		return nil, fmt.Errorf("%s is not a GitHub host (GitHub Enterprise Server hosts are set in 'githubHosts' config)", host)
+	}
+	return github.NewClient(g.Client), nil
+}
@@ -0,0 +1,73 @@
+package resolve
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/mfojtik/goodmod/pkg/config"
+)
+
+func TestNewGithubHosts(t *testing.T) {
+	for _, host := range []config.GithubHost{
+		{},
+		{Host: "github.com"},
+		{Host: "https://github.corp.example.com"},
+		{Host: "github.corp.example.com", APIURL: "github.corp.example.com/api/v3"},
+	} {
+		if _, err := NewGithubHosts(nil, []config.GithubHost{host}, 0); err == nil {
+			t.Errorf("%+v: expected error", host)
+		}
+	}
+}
+
+// TestGithubHostsAPIClient route requests of enterprise hosted module to API stand-in using the token of the host.
+func TestGithubHostsAPIClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer enterprise-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/api/v3/repos/team/api/commits/c07a134afb42" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `{"sha": "c07a134afb4217bd3dc3cd89a6e5eb5b1a2c4b6e"}`)
+	}))
+	defer server.Close()
+	defer os.Setenv("TEST_GHE_TOKEN", os.Getenv("TEST_GHE_TOKEN"))
+	os.Setenv("TEST_GHE_TOKEN", "enterprise-token")
+
+	hosts, err := NewGithubHosts(nil, []config.GithubHost{
+		{Host: "github.corp.example.com", APIURL: server.URL + "/api/v3", UploadURL: server.URL + "/api/uploads", TokenEnv: "TEST_GHE_TOKEN"},
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hosts.IsGithub("github.corp.example.com/team/api") || hosts.IsGithub("git.corp.example.com/team/api") {
+		t.Errorf("expected only github.corp.example.com to be GitHub host")
+	}
+
+	client, err := hosts.APIClient("github.corp.example.com/team/api/v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner, repo := GetGithubOwnerAndRepo(RepositoryModulePath("github.corp.example.com/team/api/v2"))
+	commit, _, err := client.Repositories.GetCommit(context.TODO(), owner, repo, "c07a134afb42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if commit.GetSHA() != "c07a134afb4217bd3dc3cd89a6e5eb5b1a2c4b6e" {
+		t.Errorf("unexpected commit %q", commit.GetSHA())
+	}
+
+	if client, err := hosts.APIClient("k8s.io/api"); err != nil || client.BaseURL.Host != "api.github.com" {
+		t.Errorf("expected github.com API client for k8s.io/api, got %v", err)
+	}
+	if _, err := hosts.APIClient("git.corp.example.com/team/api"); err == nil {
+		t.Errorf("expected error for host that is not GitHub")
+	}
+}
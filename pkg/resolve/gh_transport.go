@@ -0,0 +1,227 @@
+package resolve
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/mfojtik/goodmod/pkg/log"
+)
+
+const (
+	// DefaultRetries is the number of times a failed GitHub request is retried
+	DefaultRetries = 3
+
+	defaultBackoff    = time.Second
+	defaultMaxBackoff = time.Minute
+)
+
+// RetryTransport is a round tripper aware of GitHub rate limits. When the rate limit is exhausted, requests wait until
+// the reset time. Responses to requests exceeding primary or secondary (abuse) rate limits and transient errors are
+// retried with exponential backoff and jitter. It is safe to use from multiple goroutines.
+type RetryTransport struct {
+	Base http.RoundTripper
+	// Retries is the maximum number of times a request is retried
+	Retries int
+	// Backoff is the wait before the first retry, it doubles with every retry up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+
+	lock sync.Mutex
+	// remaining and reset are the rate limit state from the last response
+	remaining int
+	reset     time.Time
+}
+
+// NewGithubClient return the HTTP client for GitHub API that authenticate with the token (when not empty) and retry
+// failed requests.
+func NewGithubClient(token string, retries int) *http.Client {
+	var transport http.RoundTripper = &RetryTransport{Retries: retries}
+	if len(token) > 0 {
+		transport = &oauth2.Transport{Source: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}), Base: transport}
+	}
+	return &http.Client{Transport: transport}
+}
+
+func (t *RetryTransport) base() http.RoundTripper {
+	if t.Base == nil {
+		return http.DefaultTransport
+	}
+	return t.Base
+}
+
+// backoff return the wait before the retry with up to a half of it randomized, so concurrent requests do not retry at once.
+func (t *RetryTransport) backoff(attempt int) time.Duration {
+	backoff, maxBackoff := t.Backoff, t.MaxBackoff
+	if backoff == 0 {
+		backoff = defaultBackoff
+	}
+	if maxBackoff == 0 {
+		maxBackoff = defaultMaxBackoff
+	}
+	for i := 0; i < attempt && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	half := int64(backoff / 2)
+	if half == 0 {
+		return backoff
+	}
+	return time.Duration(half + rand.Int63n(half))
+}
+
+// exhaustedUntil return the reset time when the last response said there are no requests remaining.
+func (t *RetryTransport) exhaustedUntil() time.Time {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.remaining == 0 && time.Now().Before(t.reset) {
+		return t.reset
+	}
+	return time.Time{}
+}
+
+func (t *RetryTransport) recordRateLimit(resp *http.Response) {
+	remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.remaining, t.reset = remaining, time.Unix(reset, 0)
+}
+
+// isRateLimited return true when the response is GitHub rejecting the request because of primary or secondary rate limit.
+func isRateLimited(resp *http.Response) bool {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	if resp.StatusCode != http.StatusForbidden {
+		return false
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" || len(resp.Header.Get("Retry-After")) > 0 {
+		return true
+	}
+	// secondary rate limit responses are only recognizable by the message, keep the body for the caller
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return err == nil && strings.Contains(strings.ToLower(string(body)), "rate limit")
+}
+
+// isTransient return true for server errors that are likely to succeed when retried.
+func isTransient(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryWait return how long to wait before retrying the rate limited response.
+func (t *RetryTransport) retryWait(resp *http.Response, attempt int) time.Duration {
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if reset := t.exhaustedUntil(); !reset.IsZero() {
+		return time.Until(reset)
+	}
+	return t.backoff(attempt)
+}
+
+func wait(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// rewind return the request that can be sent again, requests with body that can't be read again are not retried.
+func rewind(req *http.Request) (*http.Request, bool) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, true
+	}
+	if req.GetBody == nil {
+		return nil, false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, false
+	}
+	retry := req.Clone(req.Context())
+	retry.Body = body
+	return retry, true
+}
+
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	for attempt := 0; ; attempt++ {
+		if reset := t.exhaustedUntil(); !reset.IsZero() {
+			log.Warningf("GitHub rate limit exhausted, waiting until %s ...", reset.Format(time.RFC3339))
+			if err := wait(ctx, time.Until(reset)); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := t.base().RoundTrip(req)
+		if err == nil {
+			t.recordRateLimit(resp)
+		}
+
+		var delay time.Duration
+		switch {
+		case err != nil:
+			if ctx.Err() != nil || attempt >= t.Retries {
+				return nil, err
+			}
+			delay = t.backoff(attempt)
+			log.Debugf("Request to %s failed, retrying in %s: %v", req.URL.Host, delay, err)
+		case isRateLimited(resp):
+			if attempt >= t.Retries {
+				return resp, nil
+			}
+			delay = t.retryWait(resp, attempt)
+			log.Warningf("GitHub rate limit exceeded (%s), retrying in %s ...", resp.Status, delay.Round(time.Second))
+		case isTransient(resp):
+			if attempt >= t.Retries {
+				return resp, nil
+			}
+			delay = t.backoff(attempt)
+			log.Debugf("Request to %s failed with %s, retrying in %s", req.URL.Host, resp.Status, delay)
+		default:
+			return resp, nil
+		}
+
+		retry, ok := rewind(req)
+		if !ok {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		if err := wait(ctx, delay); err != nil {
+			return nil, err
+		}
+		req = retry
+	}
+}
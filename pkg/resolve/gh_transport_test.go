@@ -0,0 +1,117 @@
+package resolve
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		respond  func(w http.ResponseWriter)
+		expected int
+		calls    int32
+	}{
+		{
+			name:     "secondary rate limit",
+			failures: 1,
+			respond: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(w, `{"message": "You have exceeded a secondary rate limit."}`)
+			},
+			expected: http.StatusOK,
+			calls:    2,
+		},
+		{
+			name:     "retry after",
+			failures: 2,
+			respond: func(w http.ResponseWriter) {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+			},
+			expected: http.StatusOK,
+			calls:    3,
+		},
+		{
+			name:     "primary rate limit reset",
+			failures: 1,
+			respond: func(w http.ResponseWriter) {
+				w.Header().Set("X-RateLimit-Remaining", "0")
+				w.Header().Set("X-RateLimit-Reset", fmt.Sprintf("%d", time.Now().Unix()))
+				w.WriteHeader(http.StatusForbidden)
+			},
+			expected: http.StatusOK,
+			calls:    2,
+		},
+		{
+			name:     "retries exhausted",
+			failures: 10,
+			respond: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusBadGateway)
+			},
+			expected: http.StatusBadGateway,
+			calls:    3,
+		},
+		{
+			name:     "permission denied",
+			failures: 10,
+			respond: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(w, `{"message": "Resource not accessible by integration"}`)
+			},
+			expected: http.StatusForbidden,
+			calls:    1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if int(atomic.AddInt32(&calls, 1)) <= test.failures {
+					test.respond(w)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			client := &http.Client{Transport: &RetryTransport{Retries: 2, Backoff: time.Millisecond}}
+			resp, err := client.Get(server.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != test.expected {
+				t.Errorf("expected status %d, got %d", test.expected, resp.StatusCode)
+			}
+			if calls != test.calls {
+				t.Errorf("expected %d calls, got %d", test.calls, calls)
+			}
+		})
+	}
+}
+
+func TestRetryTransportCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: &RetryTransport{Retries: 2}}
+	if _, err := client.Do(req.WithContext(ctx)); err == nil {
+		t.Fatal("expected error when context is canceled while waiting for retry")
+	}
+}
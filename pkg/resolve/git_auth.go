@@ -0,0 +1,258 @@
+package resolve
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	githttp "gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport/ssh"
+
+	"github.com/mfojtik/goodmod/pkg/config"
+	"github.com/mfojtik/goodmod/pkg/log"
+)
+
+// Methods of git clone authentication, selected for modules by GOPRIVATE-style patterns.
+const (
+	GitAuthNone             = "none"
+	GitAuthSSH              = "ssh"
+	GitAuthNetrc            = "netrc"
+	GitAuthCredentialHelper = "credential-helper"
+	// GitAuthAuto use the netrc entry for the host when present and the git credential helper otherwise
+	GitAuthAuto = "auto"
+)
+
+// GitAuth select the URL and authentication git clones of a module use. Modules matching no rule are cloned over
+// anonymous HTTPS. It is safe to use from multiple goroutines.
+type GitAuth struct {
+	rules     []config.GitAuthRule
+	netrcPath string
+
+	lock sync.Mutex
+	// credentials are the HTTPS credentials by host, so the credential helper is called once per host
+	credentials map[string]*githttp.BasicAuth
+}
+
+// NewGitAuth return the authentication using the rules, followed by the 'auto' method for modules matching GOPRIVATE.
+func NewGitAuth(rules []config.GitAuthRule) (*GitAuth, error) {
+	for _, r := range rules {
+		switch r.Method {
+		case GitAuthNone, GitAuthSSH, GitAuthNetrc, GitAuthCredentialHelper, GitAuthAuto:
+		default:
+			return nil, fmt.Errorf("unknown git auth method %q, must be one of: %s", r.Method, strings.Join([]string{GitAuthSSH, GitAuthNetrc, GitAuthCredentialHelper, GitAuthAuto, GitAuthNone}, ", "))
+		}
+		if len(strings.TrimSpace(r.Patterns)) == 0 {
+			return nil, fmt.Errorf("git auth method %q has no patterns", r.Method)
+		}
+	}
+	rules = append([]config.GitAuthRule{}, rules...)
+	if private := os.Getenv("GOPRIVATE"); len(private) > 0 {
+		rules = append(rules, config.GitAuthRule{Patterns: private, Method: GitAuthAuto})
+	}
+	return &GitAuth{rules: rules, netrcPath: netrcPath(), credentials: map[string]*githttp.BasicAuth{}}, nil
+}
+
+// netrcPath return the NETRC environment variable or ~/.netrc.
+func netrcPath() string {
+	if path := os.Getenv("NETRC"); len(path) > 0 {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".netrc")
+}
+
+func (a *GitAuth) rule(modulePath string) *config.GitAuthRule {
+	if a == nil {
+		return nil
+	}
+	for i := range a.rules {
+		if MatchPrefixPatterns(a.rules[i].Patterns, modulePath) {
+			return &a.rules[i]
+		}
+	}
+	return nil
+}
+
+// Endpoint return the URL the module repository is cloned from and the authentication to use, nil when anonymous.
+func (a *GitAuth) Endpoint(ctx context.Context, modulePath string) (string, transport.AuthMethod, error) {
+	repository := RepositoryModulePath(modulePath)
+	rule := a.rule(modulePath)
+	if rule == nil {
+		return repository, nil, nil
+	}
+	switch rule.Method {
+	case GitAuthSSH:
+		return sshEndpoint(repository, rule)
+	case GitAuthNetrc:
+		auth, err := a.netrcAuth(repository)
+		if err == nil && auth == nil {
+			err = fmt.Errorf("no entry in %s", a.netrcPath)
+		}
+		return repository, authMethod(auth), err
+	case GitAuthCredentialHelper:
+		auth, err := a.helperAuth(ctx, repository)
+		return repository, authMethod(auth), err
+	case GitAuthAuto:
+		auth, err := a.netrcAuth(repository)
+		if err != nil || auth != nil {
+			return repository, authMethod(auth), err
+		}
+		auth, err = a.helperAuth(ctx, repository)
+		if err != nil {
+			// the repository might still be public
+			log.WithModule(modulePath).Debugf("no git credentials found, cloning anonymously: %v", err)
+			return repository, nil, nil
+		}
+		return repository, auth, nil
+	}
+	return repository, nil, nil
+}
+
+// authMethod avoid returning typed nil as the authentication.
+func authMethod(auth *githttp.BasicAuth) transport.AuthMethod {
+	if auth == nil {
+		return nil
+	}
+	return auth
+}
+
+// sshEndpoint return the repository URL using the SSH protocol (eg. 'ssh://git@github.com/openshift/api') and the key
+// file or SSH agent authentication.
+func sshEndpoint(repository string, rule *config.GitAuthRule) (string, transport.AuthMethod, error) {
+	u, err := url.Parse(repository)
+	if err != nil {
+		return repository, nil, err
+	}
+	user := rule.SSHUser
+	if len(user) == 0 {
+		user = "git"
+	}
+	sshURL := fmt.Sprintf("ssh://%s@%s%s", user, u.Host, u.Path)
+	if len(rule.SSHKey) == 0 {
+		auth, err := ssh.NewSSHAgentAuth(user)
+		return sshURL, auth, err
+	}
+	keyFile := rule.SSHKey
+	if strings.HasPrefix(keyFile, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			keyFile = filepath.Join(home, keyFile[2:])
+		}
+	}
+	auth, err := ssh.NewPublicKeysFromFile(user, keyFile, "")
+	return sshURL, auth, err
+}
+
+// netrcAuth return the credentials for the repository host from netrc file, nil when the file has no entry for it.
+func (a *GitAuth) netrcAuth(repository string) (*githttp.BasicAuth, error) {
+	u, err := url.Parse(repository)
+	if err != nil {
+		return nil, err
+	}
+	content, err := ioutil.ReadFile(a.netrcPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	login, password, ok := netrcCredentials(string(content), u.Hostname())
+	if !ok {
+		return nil, nil
+	}
+	return &githttp.BasicAuth{Username: login, Password: password}, nil
+}
+
+// netrcCredentials return the login and password of the machine (or the default entry) from netrc file content.
+func netrcCredentials(content, host string) (string, string, bool) {
+	var (
+		login, password  string
+		current          string
+		found, inDefault bool
+	)
+	tokens := strings.Fields(content)
+	for i := 0; i < len(tokens); i++ {
+		next := func() string {
+			if i+1 < len(tokens) {
+				i++
+				return tokens[i]
+			}
+			return ""
+		}
+		switch tokens[i] {
+		case "machine", "default":
+			if found {
+				return login, password, true
+			}
+			inDefault = tokens[i] == "default"
+			current = ""
+			if !inDefault {
+				current = next()
+			}
+			found = current == host || inDefault
+			login, password = "", ""
+		case "login":
+			login = next()
+		case "password":
+			password = next()
+		case "account":
+			next()
+		case "macdef":
+			// macro definitions are not used, they end with a blank line which Fields does not preserve
+			return login, password, found
+		}
+	}
+	return login, password, found
+}
+
+// helperAuth return the credentials for the repository from 'git credential fill', the credential helpers configured
+// in git are used and git never prompts for them.
+func (a *GitAuth) helperAuth(ctx context.Context, repository string) (*githttp.BasicAuth, error) {
+	u, err := url.Parse(repository)
+	if err != nil {
+		return nil, err
+	}
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if auth, ok := a.credentials[u.Host]; ok {
+		return auth, nil
+	}
+	cmd := exec.CommandContext(ctx, "git", "credential", "fill")
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_ASKPASS=", "SSH_ASKPASS=")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("protocol=%s\nhost=%s\npath=%s\n\n", u.Scheme, u.Host, strings.TrimPrefix(u.Path, "/")))
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("git credential fill failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	auth := &githttp.BasicAuth{}
+	scanner := bufio.NewScanner(&stdout)
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		switch parts[0] {
+		case "username":
+			auth.Username = parts[1]
+		case "password":
+			auth.Password = parts[1]
+		}
+	}
+	if len(auth.Password) == 0 {
+		return nil, fmt.Errorf("git credential helper returned no password for %s", u.Host)
+	}
+	a.credentials[u.Host] = auth
+	return auth, nil
+}
@@ -0,0 +1,220 @@
+package resolve
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/cgi"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/src-d/go-git.v4/plumbing/transport/client"
+	githttp "gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+
+	"github.com/mfojtik/goodmod/pkg/config"
+)
+
+func TestNetrcCredentials(t *testing.T) {
+	content := `
+machine github.com login gh-user password gh-token
+machine git.corp.example.com
+  login corp-user
+  account ignored
+  password corp-pass
+default login anonymous password guest
+`
+	tests := []struct {
+		host     string
+		login    string
+		password string
+	}{
+		{host: "github.com", login: "gh-user", password: "gh-token"},
+		{host: "git.corp.example.com", login: "corp-user", password: "corp-pass"},
+		{host: "gitlab.com", login: "anonymous", password: "guest"},
+	}
+	for _, test := range tests {
+		t.Run(test.host, func(t *testing.T) {
+			login, password, ok := netrcCredentials(content, test.host)
+			if !ok || login != test.login || password != test.password {
+				t.Errorf("expected %s:%s, got %s:%s (found: %t)", test.login, test.password, login, password, ok)
+			}
+		})
+	}
+	if _, _, ok := netrcCredentials("machine github.com login a password b", "gitlab.com"); ok {
+		t.Errorf("expected no credentials for host without entry")
+	}
+}
+
+func TestNewGitAuth(t *testing.T) {
+	if _, err := NewGitAuth([]config.GitAuthRule{{Patterns: "github.com", Method: "kerberos"}}); err == nil {
+		t.Errorf("expected unknown method to fail")
+	}
+	if _, err := NewGitAuth([]config.GitAuthRule{{Method: GitAuthSSH}}); err == nil {
+		t.Errorf("expected rule without patterns to fail")
+	}
+}
+
+func TestGitAuthEndpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "git-auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	netrc := filepath.Join(dir, "netrc")
+	if err := ioutil.WriteFile(netrc, []byte("machine git.corp.example.com login corp-user password corp-pass\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Setenv("NETRC", os.Getenv("NETRC"))
+	os.Setenv("NETRC", netrc)
+	defer os.Setenv("GOPRIVATE", os.Getenv("GOPRIVATE"))
+	os.Setenv("GOPRIVATE", "git.corp.example.com")
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(dir, "id_ecdsa")
+	if err := ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	auth, err := NewGitAuth([]config.GitAuthRule{
+		{Patterns: "github.com/private/*", Method: GitAuthSSH, SSHKey: keyFile},
+		{Patterns: "github.com/corp", Method: GitAuthNetrc},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		modulePath string
+		url        string
+		auth       string
+		err        bool
+	}{
+		{modulePath: "github.com/openshift/api", url: "https://github.com/openshift/api"},
+		{modulePath: "github.com/private/api", url: "ssh://git@github.com/private/api", auth: "ssh-public-keys"},
+		{modulePath: "github.com/corp/api", url: "https://github.com/corp/api", err: true},
+		{modulePath: "git.corp.example.com/team/api", url: "https://git.corp.example.com/team/api", auth: "http-basic-auth"},
+	}
+	for _, test := range tests {
+		t.Run(test.modulePath, func(t *testing.T) {
+			url, method, err := auth.Endpoint(context.TODO(), test.modulePath)
+			if (err != nil) != test.err {
+				t.Fatalf("expected error %t, got %v", test.err, err)
+			}
+			if url != test.url {
+				t.Errorf("expected URL %q, got %q", test.url, url)
+			}
+			name := ""
+			if method != nil {
+				name = method.Name()
+			}
+			if !test.err && name != test.auth {
+				t.Errorf("expected %q authentication, got %q", test.auth, name)
+			}
+		})
+	}
+}
+
+// TestCloneAuth clone from git HTTP backend that require basic authentication.
+func TestCloneAuth(t *testing.T) {
+	execPath, err := exec.Command("git", "--exec-path").Output()
+	if err != nil {
+		t.Skipf("git is not available: %v", err)
+	}
+	backend := filepath.Join(strings.TrimSpace(string(execPath)), "git-http-backend")
+	if _, err := os.Stat(backend); err != nil {
+		t.Skipf("git http-backend is not available: %v", err)
+	}
+
+	dir, err := ioutil.TempDir("", "clone-auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	git := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, out)
+		}
+	}
+	git("init", "-q", "work")
+	git("-C", "work", "commit", "-q", "--allow-empty", "-m", "initial")
+	git("clone", "-q", "--bare", "work", "root/repo.git")
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "corp-user" || password != "corp-pass" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="git"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		handler := &cgi.Handler{Path: backend, Env: []string{"GIT_PROJECT_ROOT=" + filepath.Join(dir, "root"), "GIT_HTTP_EXPORT_ALL=1"}}
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	client.InstallProtocol("https", githttp.NewClient(&http.Client{
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+	}))
+	defer client.InstallProtocol("https", githttp.DefaultClient)
+
+	modulePath := strings.TrimPrefix(server.URL, "https://") + "/repo.git"
+	netrc := filepath.Join(dir, "netrc")
+	if err := ioutil.WriteFile(netrc, []byte("machine 127.0.0.1 login corp-user password corp-pass\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Setenv("NETRC", os.Getenv("NETRC"))
+	os.Setenv("NETRC", netrc)
+	defer os.Setenv("GOPRIVATE", os.Getenv("GOPRIVATE"))
+	os.Setenv("GOPRIVATE", "")
+
+	if _, err := Clone(context.TODO(), modulePath, nil); err == nil {
+		t.Errorf("expected anonymous clone to fail")
+	}
+
+	auth, err := NewGitAuth([]config.GitAuthRule{{Patterns: "127.0.0.1*", Method: GitAuthNetrc}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	repository, err := Clone(context.TODO(), modulePath, auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repository.Head(); err != nil {
+		t.Errorf("expected cloned repository to have HEAD: %v", err)
+	}
+
+	// credential helper configured in the environment instead of git config files
+	for key, value := range map[string]string{
+		"GIT_CONFIG_COUNT":   "1",
+		"GIT_CONFIG_KEY_0":   "credential.helper",
+		"GIT_CONFIG_VALUE_0": "!f() { echo username=corp-user; echo password=corp-pass; }; f",
+	} {
+		defer os.Setenv(key, os.Getenv(key))
+		os.Setenv(key, value)
+	}
+	auth, err = NewGitAuth([]config.GitAuthRule{{Patterns: "127.0.0.1*", Method: GitAuthCredentialHelper}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Clone(context.TODO(), modulePath, auth); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,38 @@
+package gomod
+
+import (
+	"context"
+	"path"
+
+	"github.com/google/go-github/v28/github"
+
+	"github.com/mfojtik/goodmod/pkg/resolve"
+	"github.com/mfojtik/goodmod/pkg/resolve/types"
+)
+
+type GithubGoModFetcher struct {
+	hosts *resolve.GithubHosts
+}
+
+func NewGithubGoModFetcher(hosts *resolve.GithubHosts) resolve.GoModFetcher {
+	return &GithubGoModFetcher{hosts: hosts}
+}
+
+func (g *GithubGoModFetcher) Fetch(ctx context.Context, modulePath string, commit *types.Commit) ([]byte, error) {
+	client, err := g.hosts.APIClient(modulePath)
+	if err != nil {
+		return nil, err
+	}
+	owner, repo := resolve.GetGithubOwnerAndRepo(resolve.RepositoryModulePath(modulePath))
+	file, _, _, err := client.Repositories.GetContents(ctx, owner, repo, path.Join(resolve.ModuleSubdirectory(modulePath), "go.mod"), &github.RepositoryContentGetOptions{
+		Ref: commit.SHA,
+	})
+	if err != nil {
+		return nil, err
+	}
+	content, err := file.GetContent()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(content), nil
+}
@@ -0,0 +1,40 @@
+package gomod
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"gopkg.in/src-d/go-git.v4/plumbing"
+
+	"github.com/mfojtik/goodmod/pkg/resolve"
+	"github.com/mfojtik/goodmod/pkg/resolve/types"
+)
+
+type GitGoModFetcher struct {
+	auth *resolve.GitAuth
+}
+
+func (g *GitGoModFetcher) Fetch(ctx context.Context, modulePath string, commit *types.Commit) ([]byte, error) {
+	repository, err := resolve.Clone(ctx, modulePath, g.auth)
+	if err != nil {
+		return nil, err
+	}
+	c, err := repository.CommitObject(plumbing.NewHash(commit.SHA))
+	if err != nil {
+		return nil, err
+	}
+	file, err := c.File(path.Join(resolve.ModuleSubdirectory(modulePath), "go.mod"))
+	if err != nil {
+		return nil, fmt.Errorf("unable to find go.mod in %s: %v", commit.SHA, err)
+	}
+	content, err := file.Contents()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(content), nil
+}
+
+func NewGitGoModFetcher(auth *resolve.GitAuth) resolve.GoModFetcher {
+	return &GitGoModFetcher{auth: auth}
+}
@@ -0,0 +1,39 @@
+package gomod
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/mfojtik/goodmod/pkg/resolve"
+	"github.com/mfojtik/goodmod/pkg/resolve/types"
+)
+
+type LocalGoModFetcher struct {
+	dirs []string
+}
+
+// NewLocalGoModFetcher return fetcher that read the go.mod file from the local clone of the module repository.
+func NewLocalGoModFetcher(dirs []string) resolve.GoModFetcher {
+	return &LocalGoModFetcher{dirs: dirs}
+}
+
+func (l *LocalGoModFetcher) Fetch(ctx context.Context, modulePath string, commit *types.Commit) ([]byte, error) {
+	repository, err := resolve.OpenLocal(l.dirs, modulePath)
+	if err != nil {
+		return nil, err
+	}
+	c, err := resolve.LocalCommit(repository, commit.SHA)
+	if err != nil {
+		return nil, err
+	}
+	file, err := c.File(path.Join(resolve.ModuleSubdirectory(modulePath), "go.mod"))
+	if err != nil {
+		return nil, fmt.Errorf("unable to find go.mod in %s: %v", commit.SHA, err)
+	}
+	content, err := file.Contents()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(content), nil
+}
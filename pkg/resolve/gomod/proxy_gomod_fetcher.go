@@ -0,0 +1,81 @@
+package gomod
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/mfojtik/goodmod/pkg/golang"
+	"github.com/mfojtik/goodmod/pkg/resolve"
+	"github.com/mfojtik/goodmod/pkg/resolve/types"
+)
+
+const defaultProxyURL = "https://proxy.golang.org"
+
+type ProxyGoModFetcher struct {
+	proxyURL string
+}
+
+// NewProxyGoModFetcher return fetcher that download the '.mod' file from Go module proxy.
+// The proxy is the first URL in GOPROXY environment variable or proxy.golang.org when not set.
+func NewProxyGoModFetcher() resolve.GoModFetcher {
+	return &ProxyGoModFetcher{proxyURL: ProxyURL()}
+}
+
+// ProxyURL return the first module proxy URL set in GOPROXY.
+func ProxyURL() string {
+	for _, p := range strings.FieldsFunc(os.Getenv("GOPROXY"), func(r rune) bool { return r == ',' || r == '|' }) {
+		if p == "direct" || p == "off" {
+			continue
+		}
+		return strings.TrimSuffix(p, "/")
+	}
+	return defaultProxyURL
+}
+
+// Fetch download the '.mod' file of the module version of the commit. The proxy is first queried for the commit
+// ('@v/<sha>.info') to get the version it knows the commit by, the tag or the pseudo-version based on the latest tag
+// (eg. 'v2.1.1-0.20191016115129-c07a134afb42' for '/v2' modules).
+func (p *ProxyGoModFetcher) Fetch(ctx context.Context, modulePath string, commit *types.Commit) ([]byte, error) {
+	escapedPath, err := golang.EscapePath(modulePath)
+	if err != nil {
+		return nil, err
+	}
+	content, err := p.get(ctx, fmt.Sprintf("%s/%s/@v/%s.info", p.proxyURL, escapedPath, commit.SHA))
+	if err != nil {
+		return nil, err
+	}
+	info := struct{ Version string }{}
+	if err := json.Unmarshal(content, &info); err != nil {
+		return nil, fmt.Errorf("unable to parse %s@%s version info: %v", modulePath, commit.SHA, err)
+	}
+	escapedVersion, err := golang.EscapeVersion(info.Version)
+	if err != nil {
+		return nil, err
+	}
+	return p.get(ctx, fmt.Sprintf("%s/%s/@v/%s.mod", p.proxyURL, escapedPath, escapedVersion))
+}
+
+func (p *ProxyGoModFetcher) get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s: %s", req.URL, resp.Status, strings.TrimSpace(string(content)))
+	}
+	return content, nil
+}
@@ -0,0 +1,56 @@
+package gomod
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mfojtik/goodmod/pkg/resolve/types"
+)
+
+func TestProxyGoModFetcher(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/k8s.io/api/@v/35e52d86657a9a6a4ad19d1432e3a8a6a51af3e3.info":
+			fmt.Fprint(w, `{"Version": "v0.16.2", "Time": "2019-10-15T19:18:26Z"}`)
+		case "/k8s.io/api/@v/v0.16.2.mod":
+			fmt.Fprint(w, "module k8s.io/api\n")
+		case "/github.com/!burnt!sushi/toml/v2/@v/c07a134afb4217bd3dc3cd89a6e5eb5b1a2c4b6e.info":
+			fmt.Fprint(w, `{"Version": "v2.1.1-0.20191016115129-c07a134afb42", "Time": "2019-10-16T11:51:29Z"}`)
+		case "/github.com/!burnt!sushi/toml/v2/@v/v2.1.1-0.20191016115129-c07a134afb42.mod":
+			fmt.Fprint(w, "module github.com/BurntSushi/toml/v2\n")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	f := &ProxyGoModFetcher{proxyURL: server.URL}
+	for _, test := range []struct {
+		modulePath string
+		sha        string
+		expected   string
+	}{
+		{modulePath: "k8s.io/api", sha: "35e52d86657a9a6a4ad19d1432e3a8a6a51af3e3", expected: "module k8s.io/api\n"},
+		{modulePath: "github.com/BurntSushi/toml/v2", sha: "c07a134afb4217bd3dc3cd89a6e5eb5b1a2c4b6e", expected: "module github.com/BurntSushi/toml/v2\n"},
+		{modulePath: "k8s.io/api", sha: "c07a134afb4217bd3dc3cd89a6e5eb5b1a2c4b6e"},
+	} {
+		content, err := f.Fetch(context.TODO(), test.modulePath, &types.Commit{SHA: test.sha, Timestamp: time.Now()})
+		if len(test.expected) == 0 {
+			if err == nil {
+				t.Errorf("%s@%s: expected error", test.modulePath, test.sha)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s@%s: unexpected error: %v", test.modulePath, test.sha, err)
+			continue
+		}
+		if string(content) != test.expected {
+			t.Errorf("%s@%s: expected %q, got %q", test.modulePath, test.sha, test.expected, content)
+		}
+	}
+}
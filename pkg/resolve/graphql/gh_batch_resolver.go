@@ -0,0 +1,211 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mfojtik/goodmod/pkg/log"
+	"github.com/mfojtik/goodmod/pkg/resolve"
+	"github.com/mfojtik/goodmod/pkg/resolve/types"
+)
+
+// DefaultEndpoint is the GitHub GraphQL API endpoint.
+const DefaultEndpoint = "https://api.github.com/graphql"
+
+// batchSize is the number of refs resolved by a single query, it keeps the query under GitHub node limits.
+const batchSize = 50
+
+// Ref is a branch, tag or commit of the module repository to resolve.
+type Ref struct {
+	ModulePath string
+	Kind       resolve.RefKind
+	Name       string
+}
+
+// Result is the commit the ref resolved to or the error why it can't be resolved.
+type Result struct {
+	Commit *types.Commit
+	Err    error
+}
+
+// GithubBatchResolver resolve refs of many repositories using a single GraphQL query with an alias for every ref.
+// Annotated tags are peeled to the commit they point to.
+type GithubBatchResolver struct {
+	oauthClient *http.Client
+	endpoint    string
+}
+
+// NewGithubBatchResolver return the resolver using the GraphQL endpoint (DefaultEndpoint when empty). GitHub require
+// the GraphQL requests to be authenticated.
+func NewGithubBatchResolver(oauthClient *http.Client, endpoint string) *GithubBatchResolver {
+	if oauthClient == nil {
+		oauthClient = http.DefaultClient
+	}
+	if len(endpoint) == 0 {
+		endpoint = DefaultEndpoint
+	}
+	return &GithubBatchResolver{oauthClient: oauthClient, endpoint: endpoint}
+}
+
+const fragments = `
+fragment commit on Commit { oid committedDate }
+fragment peeled on GitObject { __typename ...commit ... on Tag { target { __typename ...commit ... on Tag { target { __typename ...commit } } } } }`
+
+func quote(s string) string {
+	out, _ := json.Marshal(s)
+	return string(out)
+}
+
+func refSelection(ref Ref) string {
+	switch ref.Kind {
+	case resolve.BranchRef:
+		return fmt.Sprintf("ref(qualifiedName: %s) { target { ...peeled } }", quote("refs/heads/"+ref.Name))
+	case resolve.TagRef:
+		return fmt.Sprintf("ref(qualifiedName: %s) { target { ...peeled } }", quote("refs/tags/"+ref.Name))
+	default:
+		return fmt.Sprintf("object(expression: %s) { ...peeled }", quote(ref.Name))
+	}
+}
+
+// query return the query resolving all refs, the ref at index i use alias 'r<i>'.
+func query(refs []Ref) string {
+	var q strings.Builder
+	q.WriteString("query {\n")
+	for i, ref := range refs {
+		owner, repo := resolve.GetGithubOwnerAndRepo(resolve.RepositoryModulePath(ref.ModulePath))
+		fmt.Fprintf(&q, "  r%d: repository(owner: %s, name: %s) { %s }\n", i, quote(owner), quote(repo), refSelection(ref))
+	}
+	q.WriteString("}")
+	q.WriteString(fragments)
+	return q.String()
+}
+
+type gitObject struct {
+	Typename      string     `json:"__typename"`
+	OID           string     `json:"oid"`
+	CommittedDate time.Time  `json:"committedDate"`
+	Target        *gitObject `json:"target"`
+}
+
+type repository struct {
+	Ref *struct {
+		Target *gitObject `json:"target"`
+	} `json:"ref"`
+	Object *gitObject `json:"object"`
+}
+
+type response struct {
+	Data   map[string]*repository `json:"data"`
+	Errors []struct {
+		Message string        `json:"message"`
+		Path    []interface{} `json:"path"`
+	} `json:"errors"`
+}
+
+// peel follow annotated tags to the commit.
+func peel(o *gitObject) (*types.Commit, error) {
+	for o != nil && o.Typename == "Tag" {
+		o = o.Target
+	}
+	if o == nil || o.Typename != "Commit" {
+		return nil, fmt.Errorf("does not point to a commit")
+	}
+	return &types.Commit{SHA: o.OID, Timestamp: o.CommittedDate}, nil
+}
+
+func (g *GithubBatchResolver) post(ctx context.Context, q string) (*response, error) {
+	body, err := json.Marshal(map[string]string{"query": q})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, g.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := g.oauthClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	out, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(out)))
+	}
+	result := &response{}
+	if err := json.Unmarshal(out, result); err != nil {
+		return nil, fmt.Errorf("unable to parse response: %v", err)
+	}
+	return result, nil
+}
+
+func (g *GithubBatchResolver) resolveBatch(ctx context.Context, refs []Ref, results map[Ref]Result) error {
+	log.Tracef("Resolving %d refs using GitHub GraphQL API ...", len(refs))
+	resp, err := g.post(ctx, query(refs))
+	if err != nil {
+		return err
+	}
+	errors := map[string]string{}
+	for _, e := range resp.Errors {
+		if len(e.Path) > 0 {
+			errors[fmt.Sprintf("%v", e.Path[0])] = e.Message
+		}
+	}
+	for i, ref := range refs {
+		alias := fmt.Sprintf("r%d", i)
+		repo := resp.Data[alias]
+		var object *gitObject
+		switch {
+		case len(errors[alias]) > 0:
+			results[ref] = Result{Err: fmt.Errorf("%s", errors[alias])}
+			continue
+		case repo == nil:
+			results[ref] = Result{Err: fmt.Errorf("repository not found")}
+			continue
+		case ref.Kind == resolve.CommitRef:
+			object = repo.Object
+		case repo.Ref != nil:
+			object = repo.Ref.Target
+		}
+		if object == nil {
+			results[ref] = Result{Err: fmt.Errorf("%s %q not found", ref.Kind, ref.Name)}
+			continue
+		}
+		c, err := peel(object)
+		if err != nil {
+			results[ref] = Result{Err: fmt.Errorf("%s %q %v", ref.Kind, ref.Name, err)}
+			continue
+		}
+		results[ref] = Result{Commit: c}
+	}
+	return nil
+}
+
+// ResolveAll resolve all refs using one query for every batchSize refs. The error is returned when a query fails, the
+// result then include only refs resolved by the other queries.
+func (g *GithubBatchResolver) ResolveAll(ctx context.Context, refs []Ref) (map[Ref]Result, error) {
+	results := map[Ref]Result{}
+	var lastErr error
+	for start := 0; start < len(refs); start += batchSize {
+		end := start + batchSize
+		if end > len(refs) {
+			end = len(refs)
+		}
+		if err := g.resolveBatch(ctx, refs[start:end], results); err != nil {
+			if ctx.Err() != nil {
+				return results, ctx.Err()
+			}
+			lastErr = err
+		}
+	}
+	return results, lastErr
+}
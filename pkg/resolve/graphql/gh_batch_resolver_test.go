@@ -0,0 +1,134 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/mfojtik/goodmod/pkg/resolve"
+)
+
+var aliasRegexp = regexp.MustCompile(`(r\d+): repository\(owner: "([^"]+)", name: "([^"]+)"\) \{ (ref\(qualifiedName|object\(expression): "([^"]+)"`)
+
+// fakeGithub is a GraphQL stand-in that answer aliased repository queries from objects keyed by 'owner/repo@ref'.
+func fakeGithub(t *testing.T, objects map[string]interface{}, requests *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		var body struct {
+			Query string `json:"query"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		data := map[string]interface{}{}
+		errors := []interface{}{}
+		for _, m := range aliasRegexp.FindAllStringSubmatch(body.Query, -1) {
+			alias, repo, selector, name := m[1], m[2]+"/"+m[3], m[4], m[5]
+			if repo == "openshift/missing" {
+				data[alias] = nil
+				errors = append(errors, map[string]interface{}{"message": "Could not resolve to a Repository", "path": []string{alias}})
+				continue
+			}
+			object := objects[repo+"@"+name]
+			if selector == "ref(qualifiedName" {
+				if object == nil {
+					data[alias] = map[string]interface{}{"ref": nil}
+				} else {
+					data[alias] = map[string]interface{}{"ref": map[string]interface{}{"target": object}}
+				}
+				continue
+			}
+			data[alias] = map[string]interface{}{"object": object}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": data, "errors": errors})
+	}))
+}
+
+func commitObject(sha string, date time.Time) map[string]interface{} {
+	return map[string]interface{}{"__typename": "Commit", "oid": sha, "committedDate": date.Format(time.RFC3339)}
+}
+
+func TestResolveAll(t *testing.T) {
+	date := time.Date(2019, 10, 16, 11, 4, 8, 0, time.UTC)
+	objects := map[string]interface{}{
+		"openshift/api@refs/heads/master": commitObject("1111111111111111111111111111111111111111", date),
+		"kubernetes/api@refs/tags/kubernetes-1.16.2": map[string]interface{}{
+			"__typename": "Tag",
+			"target":     commitObject("2222222222222222222222222222222222222222", date),
+		},
+		"kubernetes/apimachinery@refs/tags/v0.16.2":                    commitObject("3333333333333333333333333333333333333333", date),
+		"openshift/client-go@4444444444444444444444444444444444444444": commitObject("4444444444444444444444444444444444444444", date),
+	}
+	var requests int
+	server := fakeGithub(t, objects, &requests)
+	defer server.Close()
+
+	refs := []Ref{
+		{ModulePath: "github.com/openshift/api", Kind: resolve.BranchRef, Name: "master"},
+		{ModulePath: "k8s.io/api", Kind: resolve.TagRef, Name: "kubernetes-1.16.2"},
+		{ModulePath: "k8s.io/apimachinery", Kind: resolve.TagRef, Name: "v0.16.2"},
+		{ModulePath: "github.com/openshift/client-go", Kind: resolve.CommitRef, Name: "4444444444444444444444444444444444444444"},
+		{ModulePath: "github.com/openshift/api", Kind: resolve.BranchRef, Name: "release-4.2"},
+		{ModulePath: "github.com/openshift/missing", Kind: resolve.BranchRef, Name: "master"},
+	}
+	results, err := NewGithubBatchResolver(server.Client(), server.URL).ResolveAll(context.TODO(), refs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests != 1 {
+		t.Errorf("expected all refs resolved by one request, got %d", requests)
+	}
+
+	expected := map[string]string{
+		"master":            "v0.0.0-20191016110408-111111111111",
+		"kubernetes-1.16.2": "v0.0.0-20191016110408-222222222222",
+		"v0.16.2":           "v0.0.0-20191016110408-333333333333",
+		"4444444444444444444444444444444444444444": "v0.0.0-20191016110408-444444444444",
+	}
+	for _, ref := range refs[:4] {
+		result := results[ref]
+		if result.Err != nil {
+			t.Errorf("%s: unexpected error: %v", ref.ModulePath, result.Err)
+			continue
+		}
+		if result.Commit.String() != expected[ref.Name] {
+			t.Errorf("%s: expected %s, got %s", ref.ModulePath, expected[ref.Name], result.Commit.String())
+		}
+	}
+	for _, ref := range refs[4:] {
+		if results[ref].Err == nil {
+			t.Errorf("%s@%s: expected error", ref.ModulePath, ref.Name)
+		}
+	}
+}
+
+func TestResolveAllBatches(t *testing.T) {
+	objects := map[string]interface{}{}
+	refs := []Ref{}
+	for i := 0; i < batchSize*2+1; i++ {
+		sha := fmt.Sprintf("%040d", i)
+		objects[fmt.Sprintf("openshift/repo-%d@refs/heads/master", i)] = commitObject(sha, time.Now())
+		refs = append(refs, Ref{ModulePath: fmt.Sprintf("github.com/openshift/repo-%d", i), Kind: resolve.BranchRef, Name: "master"})
+	}
+	var requests int
+	server := fakeGithub(t, objects, &requests)
+	defer server.Close()
+
+	results, err := NewGithubBatchResolver(server.Client(), server.URL).ResolveAll(context.TODO(), refs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests != 3 {
+		t.Errorf("expected 3 requests, got %d", requests)
+	}
+	for i, ref := range refs {
+		if result := results[ref]; result.Err != nil || result.Commit.SHA != fmt.Sprintf("%040d", i) {
+			t.Errorf("%s: unexpected result %+v", ref.ModulePath, result)
+		}
+	}
+}
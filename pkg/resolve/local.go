@@ -0,0 +1,141 @@
+package resolve
+
+import (
+	"fmt"
+	"go/build"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// DefaultLocalRepos return the GOPATH-style directories (eg. '~/go/src') local clones are searched in.
+func DefaultLocalRepos() []string {
+	dirs := []string{}
+	for _, dir := range filepath.SplitList(build.Default.GOPATH) {
+		dirs = append(dirs, filepath.Join(dir, "src"))
+	}
+	return dirs
+}
+
+// localCandidates return the paths relative to the search directory the module repository can be cloned at, the module
+// path and its parents first (eg. 'k8s.io/api') and the repository path and its parents after (eg.
+// 'github.com/kubernetes/api').
+func localCandidates(modulePath string) []string {
+	candidates := []string{}
+	for _, p := range []string{modulePath, strings.TrimPrefix(RepositoryModulePath(modulePath), "https://")} {
+		parts := strings.Split(p, "/")
+		for i := len(parts); i >= 2; i-- {
+			candidates = append(candidates, filepath.FromSlash(strings.Join(parts[:i], "/")))
+		}
+	}
+	return candidates
+}
+
+// OpenLocal open the existing clone of the module repository found in the search directories (eg. GOPATH-style
+// '~/go/src' with the clone in 'github.com/openshift/api'). The network is never used.
+func OpenLocal(dirs []string, modulePath string) (*git.Repository, error) {
+	for _, dir := range dirs {
+		if strings.HasPrefix(dir, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				dir = filepath.Join(home, dir[2:])
+			}
+		}
+		for _, candidate := range localCandidates(modulePath) {
+			path := filepath.Join(dir, candidate)
+			if _, err := os.Stat(path); err != nil {
+				continue
+			}
+			repository, err := git.PlainOpen(path)
+			if err == git.ErrRepositoryNotExists {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("unable to open local repository %s: %v", path, err)
+			}
+			return repository, nil
+		}
+	}
+	return nil, fmt.Errorf("no local clone of %s found in %s", modulePath, strings.Join(dirs, ", "))
+}
+
+// LocalBranch return the commit at the head of the local branch or the remote-tracking branch (eg.
+// 'refs/remotes/origin/master'), the 'origin' remote is preferred over other remotes.
+func LocalBranch(repository *git.Repository, name string) (*object.Commit, error) {
+	if ref, err := repository.Reference(plumbing.NewBranchReferenceName(name), true); err == nil {
+		return repository.CommitObject(ref.Hash())
+	}
+	if ref, err := repository.Reference(plumbing.NewRemoteReferenceName("origin", name), true); err == nil {
+		return repository.CommitObject(ref.Hash())
+	}
+	refs, err := repository.References()
+	if err != nil {
+		return nil, err
+	}
+	var found *plumbing.Reference
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if !ref.Name().IsRemote() {
+			return nil
+		}
+		// refs/remotes/<remote>/<branch>
+		if parts := strings.SplitN(strings.TrimPrefix(ref.Name().String(), "refs/remotes/"), "/", 2); len(parts) == 2 && parts[1] == name {
+			found = ref
+			return io.EOF
+		}
+		return nil
+	})
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if found == nil {
+		return nil, fmt.Errorf("unable to find branch %s", name)
+	}
+	if found.Type() == plumbing.SymbolicReference {
+		if found, err = repository.Reference(found.Name(), true); err != nil {
+			return nil, err
+		}
+	}
+	return repository.CommitObject(found.Hash())
+}
+
+// LocalTag return the commit the lightweight or annotated tag point to.
+func LocalTag(repository *git.Repository, name string) (*object.Commit, error) {
+	ref, err := repository.Reference(plumbing.NewTagReferenceName(name), true)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find tag %s: %v", name, err)
+	}
+	if tag, err := repository.TagObject(ref.Hash()); err == nil {
+		return tag.Commit()
+	}
+	return repository.CommitObject(ref.Hash())
+}
+
+// LocalCommit return the commit with the SHA, abbreviated SHA (eg. from pseudo-version) is accepted.
+func LocalCommit(repository *git.Repository, sha string) (*object.Commit, error) {
+	if len(sha) == 40 {
+		return repository.CommitObject(plumbing.NewHash(sha))
+	}
+	commits, err := repository.CommitObjects()
+	if err != nil {
+		return nil, err
+	}
+	var found *object.Commit
+	err = commits.ForEach(func(c *object.Commit) error {
+		if strings.HasPrefix(c.Hash.String(), sha) {
+			found = c
+			return io.EOF
+		}
+		return nil
+	})
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if found == nil {
+		return nil, fmt.Errorf("unable to find commit %s", sha)
+	}
+	return found, nil
+}
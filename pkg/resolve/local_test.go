@@ -0,0 +1,86 @@
+package resolve
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// gitRepository create repository in the directory using git commands and return function that run git in it.
+func gitRepository(t *testing.T, dir string) func(args ...string) string {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skipf("git is not available: %v", err)
+	}
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	git("init", "-q")
+	return git
+}
+
+func TestLocalRefs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "local")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	upstream := gitRepository(t, filepath.Join(dir, "upstream"))
+	upstream("commit", "-q", "--allow-empty", "-m", "initial")
+	upstream("tag", "-a", "v1.0.0", "-m", "v1.0.0")
+	initial := upstream("rev-parse", "HEAD")
+	upstream("checkout", "-q", "-b", "release-1.0")
+	upstream("commit", "-q", "--allow-empty", "-m", "release")
+	upstream("tag", "v1.0.1")
+	release := upstream("rev-parse", "HEAD")
+
+	// GOPATH-style clone that only has the release branch as remote-tracking branch
+	src := filepath.Join(dir, "src")
+	if err := os.MkdirAll(filepath.Join(src, "github.com", "example"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	clone := exec.Command("git", "clone", "-q", "-b", "v1.0.0", filepath.Join(dir, "upstream"), filepath.Join(src, "github.com", "example", "lib"))
+	if out, err := clone.CombinedOutput(); err != nil {
+		t.Fatalf("git clone: %v: %s", err, out)
+	}
+
+	if _, err := OpenLocal([]string{src}, "github.com/example/other"); err == nil {
+		t.Errorf("expected error for module without local clone")
+	}
+	repository, err := OpenLocal([]string{filepath.Join(dir, "missing"), src}, "github.com/example/lib/v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c, err := LocalBranch(repository, "release-1.0"); err != nil || c.Hash.String() != release {
+		t.Errorf("expected remote-tracking branch at %s, got %v (%v)", release, c, err)
+	}
+	if _, err := LocalBranch(repository, "1.0"); err == nil {
+		t.Errorf("expected error for branch matching only the suffix of remote-tracking branch")
+	}
+	if c, err := LocalTag(repository, "v1.0.0"); err != nil || c.Hash.String() != initial {
+		t.Errorf("expected annotated tag at %s, got %v (%v)", initial, c, err)
+	}
+	if c, err := LocalTag(repository, "v1.0.1"); err != nil || c.Hash.String() != release {
+		t.Errorf("expected lightweight tag at %s, got %v (%v)", release, c, err)
+	}
+	if c, err := LocalCommit(repository, release[:12]); err != nil || c.Hash.String() != release {
+		t.Errorf("expected abbreviated commit %s, got %v (%v)", release, c, err)
+	}
+	if _, err := LocalCommit(repository, "000000000000"); err == nil {
+		t.Errorf("expected error for unknown commit")
+	}
+}
@@ -0,0 +1,174 @@
+package modsource
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/filemode"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+
+	"github.com/mfojtik/goodmod/pkg/golang"
+	"github.com/mfojtik/goodmod/pkg/resolve"
+	"github.com/mfojtik/goodmod/pkg/resolve/types"
+)
+
+type GitModuleFetcher struct {
+	auth *resolve.GitAuth
+}
+
+// NewGitModuleFetcher return fetcher that build the module content from git repository using the module zip rules.
+func NewGitModuleFetcher(auth *resolve.GitAuth) resolve.ModuleFetcher {
+	return &GitModuleFetcher{auth: auth}
+}
+
+// versionCommit find the commit for pseudo-version or version tag.
+func versionCommit(repository *git.Repository, modulePath, version string) (*object.Commit, error) {
+	if sha, ok := golang.PseudoVersionCommit(version); ok {
+		commits, err := repository.CommitObjects()
+		if err != nil {
+			return nil, err
+		}
+		var found *object.Commit
+		err = commits.ForEach(func(c *object.Commit) error {
+			if strings.HasPrefix(c.Hash.String(), sha) {
+				found = c
+				return io.EOF
+			}
+			return nil
+		})
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if found == nil {
+			return nil, fmt.Errorf("unable to find commit %s", sha)
+		}
+		return found, nil
+	}
+	tagName := strings.TrimSuffix(version, "+incompatible")
+	if subdir := resolve.ModuleSubdirectory(modulePath); len(subdir) > 0 {
+		tagName = subdir + "/" + tagName
+	}
+	ref, err := repository.Storer.Reference(plumbing.NewTagReferenceName(tagName))
+	if err != nil {
+		return nil, fmt.Errorf("unable to find tag %s: %v", tagName, err)
+	}
+	if tag, err := repository.TagObject(ref.Hash()); err == nil {
+		return tag.Commit()
+	}
+	return repository.CommitObject(ref.Hash())
+}
+
+// isVendoredPackage return true for files in vendored packages that are excluded from module zip.
+func isVendoredPackage(name string) bool {
+	var i int
+	if strings.HasPrefix(name, "vendor/") {
+		i += len("vendor/")
+	} else if j := strings.Index(name, "/vendor/"); j >= 0 {
+		i += j + len("/vendor/")
+	} else {
+		return false
+	}
+	return strings.Contains(name[i:], "/")
+}
+
+func isVCSDirectory(name string) bool {
+	for _, part := range strings.Split(path.Dir(name), "/") {
+		switch part {
+		case ".git", ".hg", ".svn", ".bzr":
+			return true
+		}
+	}
+	return false
+}
+
+// moduleFiles return the files that are included in module zip. Files in nested modules, vendored packages, version
+// control directories and symlinks are excluded.
+func moduleFiles(tree *object.Tree) ([]*object.File, error) {
+	nestedModules := []string{}
+	files := []*object.File{}
+	err := tree.Files().ForEach(func(f *object.File) error {
+		if path.Base(f.Name) == "go.mod" && f.Name != "go.mod" {
+			nestedModules = append(nestedModules, path.Dir(f.Name)+"/")
+		}
+		files = append(files, f)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := []*object.File{}
+	for _, f := range files {
+		if f.Mode != filemode.Regular && f.Mode != filemode.Executable {
+			continue
+		}
+		if isVendoredPackage(f.Name) || isVCSDirectory(f.Name) {
+			continue
+		}
+		nested := false
+		for _, dir := range nestedModules {
+			if strings.HasPrefix(f.Name, dir) {
+				nested = true
+				break
+			}
+		}
+		if !nested {
+			result = append(result, f)
+		}
+	}
+	return result, nil
+}
+
+func (g *GitModuleFetcher) Fetch(ctx context.Context, modulePath string, version string) (*types.Module, error) {
+	repository, err := resolve.Clone(ctx, modulePath, g.auth)
+	if err != nil {
+		return nil, err
+	}
+	return repositoryModule(repository, modulePath, version)
+}
+
+// repositoryModule build the module version content from the repository.
+func repositoryModule(repository *git.Repository, modulePath string, version string) (*types.Module, error) {
+	commit, err := versionCommit(repository, modulePath, version)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	if subdir := resolve.ModuleSubdirectory(modulePath); len(subdir) > 0 {
+		if tree, err = tree.Tree(subdir); err != nil {
+			return nil, fmt.Errorf("unable to find module directory %q: %v", subdir, err)
+		}
+	}
+	files, err := moduleFiles(tree)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &types.Module{Path: modulePath, Version: version}
+	for _, f := range files {
+		file := f
+		if file.Name == "go.mod" {
+			content, err := file.Contents()
+			if err != nil {
+				return nil, err
+			}
+			m.GoMod = []byte(content)
+		}
+		m.Files = append(m.Files, types.ModuleFile{
+			Name: file.Name,
+			Open: func() (io.ReadCloser, error) { return file.Reader() },
+		})
+	}
+	if m.GoMod == nil {
+		// modules without go.mod file get synthesized one
+		m.GoMod = []byte(fmt.Sprintf("module %s\n", golang.AutoQuote(modulePath)))
+	}
+	return m, nil
+}
@@ -0,0 +1,144 @@
+package modsource
+
+import (
+	"archive/zip"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// gitRepository create repository in the directory using git commands and return function that run git in it.
+func gitRepository(t *testing.T, dir string) func(args ...string) string {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skipf("git is not available: %v", err)
+	}
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	git("init", "-q")
+	return git
+}
+
+func writeFile(t *testing.T, path, content string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// extractModuleZip write the files of the module zip in testdata to the directory.
+func extractModuleZip(t *testing.T, name, dir string) {
+	r, err := zip.OpenReader(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	for _, f := range r.File {
+		in, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := ioutil.ReadAll(in)
+		in.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeFile(t, filepath.Join(dir, strings.SplitN(f.Name, "/", 4)[3]), string(content))
+	}
+}
+
+func TestRepositoryModule(t *testing.T) {
+	dir, err := ioutil.TempDir("", "modsource")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	run := gitRepository(t, dir)
+	extractModuleZip(t, "testdata/go-shlex.zip", dir)
+	// files excluded from the module zip
+	writeFile(t, filepath.Join(dir, "vendor", "github.com", "pkg", "errors", "errors.go"), "package errors\n")
+	writeFile(t, filepath.Join(dir, ".hg", "hgrc"), "[paths]\n")
+	writeFile(t, filepath.Join(dir, "nested", "go.mod"), "module github.com/anmitsu/go-shlex/nested\n")
+	writeFile(t, filepath.Join(dir, "nested", "nested.go"), "package nested\n")
+	writeFile(t, filepath.Join(dir, "nested", "vendor", "modules.txt"), "# explicit\n")
+	if err := os.Symlink("README.md", filepath.Join(dir, "README.link")); err != nil {
+		t.Fatal(err)
+	}
+	run("add", "-A")
+	run("commit", "-q", "-m", "initial")
+	run("tag", "nested/v1.0.0")
+	sha := run("rev-parse", "HEAD")
+	repository, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		modulePath string
+		version    string
+		files      []string
+		goMod      string
+	}{
+		{
+			modulePath: shlexPath,
+			version:    "v0.0.0-20161002113705-" + sha[:12],
+			files:      []string{".gitignore", "LICENSE", "README.md", "example_test.go", "shlex.go", "shlex_test.go"},
+			goMod:      "module github.com/anmitsu/go-shlex\n",
+		},
+		{
+			modulePath: shlexPath + "/nested",
+			version:    "v1.0.0",
+			files:      []string{"go.mod", "nested.go", "vendor/modules.txt"},
+			goMod:      "module github.com/anmitsu/go-shlex/nested\n",
+		},
+	} {
+		m, err := repositoryModule(repository, tc.modulePath, tc.version)
+		if err != nil {
+			t.Errorf("%s@%s: %v", tc.modulePath, tc.version, err)
+			continue
+		}
+		files := []string{}
+		for _, f := range m.Files {
+			files = append(files, f.Name)
+		}
+		sort.Strings(files)
+		if !reflect.DeepEqual(files, tc.files) {
+			t.Errorf("%s@%s: expected files %v, got %v", tc.modulePath, tc.version, tc.files, files)
+		}
+		if string(m.GoMod) != tc.goMod {
+			t.Errorf("%s@%s: expected go.mod %q, got %q", tc.modulePath, tc.version, tc.goMod, m.GoMod)
+		}
+	}
+
+	// the files of the commit hash the same as the published module version
+	m, err := repositoryModule(repository, shlexPath, "v0.0.0-20161002113705-"+sha[:12])
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Version = shlexVersion
+	if hash, err := Hash(m); err != nil || hash != shlexHash {
+		t.Errorf("expected %s, got %s (%v)", shlexHash, hash, err)
+	}
+	if hash, err := HashGoMod(m); err != nil || hash != shlexGoModHash {
+		t.Errorf("expected go.mod %s, got %s (%v)", shlexGoModHash, hash, err)
+	}
+}
@@ -0,0 +1,28 @@
+package modsource
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/mfojtik/goodmod/pkg/golang"
+	"github.com/mfojtik/goodmod/pkg/resolve/types"
+)
+
+// Hash compute the go.sum hash of the module zip content.
+func Hash(m *types.Module) (string, error) {
+	prefix := fmt.Sprintf("%s@%s/", m.Path, m.Version)
+	names := []string{}
+	files := map[string]types.ModuleFile{}
+	for _, f := range m.Files {
+		names = append(names, prefix+f.Name)
+		files[prefix+f.Name] = f
+	}
+	return golang.Hash1(names, func(name string) (io.ReadCloser, error) {
+		return files[name].Open()
+	})
+}
+
+// HashGoMod compute the go.sum hash of the module go.mod file.
+func HashGoMod(m *types.Module) (string, error) {
+	return golang.HashGoMod(m.GoMod)
+}
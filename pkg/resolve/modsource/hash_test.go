@@ -0,0 +1,47 @@
+package modsource
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// go.sum lines of the module zip in testdata (downloaded from proxy.golang.org)
+const (
+	shlexPath      = "github.com/anmitsu/go-shlex"
+	shlexVersion   = "v0.0.0-20161002113705-648efa622239"
+	shlexHash      = "h1:kFOfPq6dUM1hTo4JG6LR5AXSUEsOjtdm0kw0FtQtMJA="
+	shlexGoModHash = "h1:2FmKhYUyUczH0OGQWaF5ceTx0UBShxjsH6f8oGKYe2c="
+)
+
+func TestHash(t *testing.T) {
+	zipContent, err := ioutil.ReadFile("testdata/go-shlex.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/" + shlexPath + "/@v/" + shlexVersion + ".mod":
+			// the module has no go.mod file, the proxy serve the synthesized one
+			w.Write([]byte("module " + shlexPath + "\n"))
+		case "/" + shlexPath + "/@v/" + shlexVersion + ".zip":
+			w.Write(zipContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	m, err := (&ProxyModuleFetcher{proxyURL: server.URL}).Fetch(context.TODO(), shlexPath, shlexVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash, err := Hash(m); err != nil || hash != shlexHash {
+		t.Errorf("expected %s, got %s (%v)", shlexHash, hash, err)
+	}
+	if hash, err := HashGoMod(m); err != nil || hash != shlexGoModHash {
+		t.Errorf("expected go.mod %s, got %s (%v)", shlexGoModHash, hash, err)
+	}
+}
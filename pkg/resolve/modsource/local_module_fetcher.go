@@ -0,0 +1,25 @@
+package modsource
+
+import (
+	"context"
+
+	"github.com/mfojtik/goodmod/pkg/resolve"
+	"github.com/mfojtik/goodmod/pkg/resolve/types"
+)
+
+type LocalModuleFetcher struct {
+	dirs []string
+}
+
+// NewLocalModuleFetcher return fetcher that build the module content from the local clone of the module repository.
+func NewLocalModuleFetcher(dirs []string) resolve.ModuleFetcher {
+	return &LocalModuleFetcher{dirs: dirs}
+}
+
+func (l *LocalModuleFetcher) Fetch(ctx context.Context, modulePath string, version string) (*types.Module, error) {
+	repository, err := resolve.OpenLocal(l.dirs, modulePath)
+	if err != nil {
+		return nil, err
+	}
+	return repositoryModule(repository, modulePath, version)
+}
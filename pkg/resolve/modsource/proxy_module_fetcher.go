@@ -0,0 +1,85 @@
+package modsource
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/mfojtik/goodmod/pkg/golang"
+	"github.com/mfojtik/goodmod/pkg/resolve"
+	"github.com/mfojtik/goodmod/pkg/resolve/gomod"
+	"github.com/mfojtik/goodmod/pkg/resolve/types"
+)
+
+type ProxyModuleFetcher struct {
+	proxyURL string
+}
+
+// NewProxyModuleFetcher return fetcher that download the module zip and go.mod file from Go module proxy.
+func NewProxyModuleFetcher() resolve.ModuleFetcher {
+	return &ProxyModuleFetcher{proxyURL: gomod.ProxyURL()}
+}
+
+func (p *ProxyModuleFetcher) get(ctx context.Context, modulePath, version, suffix string) ([]byte, error) {
+	escapedPath, err := golang.EscapePath(modulePath)
+	if err != nil {
+		return nil, err
+	}
+	escapedVersion, err := golang.EscapeVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/%s/@v/%s%s", p.proxyURL, escapedPath, escapedVersion, suffix), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s: %s", req.URL, resp.Status, strings.TrimSpace(string(content)))
+	}
+	return content, nil
+}
+
+func (p *ProxyModuleFetcher) Fetch(ctx context.Context, modulePath string, version string) (*types.Module, error) {
+	goMod, err := p.get(ctx, modulePath, version, ".mod")
+	if err != nil {
+		return nil, err
+	}
+	zipContent, err := p.get(ctx, modulePath, version, ".zip")
+	if err != nil {
+		return nil, err
+	}
+	zipReader, err := zip.NewReader(bytes.NewReader(zipContent), int64(len(zipContent)))
+	if err != nil {
+		return nil, err
+	}
+	m := &types.Module{Path: modulePath, Version: version, GoMod: goMod}
+	prefix := fmt.Sprintf("%s@%s/", modulePath, version)
+	for _, f := range zipReader.File {
+		if !strings.HasPrefix(f.Name, prefix) {
+			return nil, fmt.Errorf("unexpected file %q in module zip", f.Name)
+		}
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		zipFile := f
+		m.Files = append(m.Files, types.ModuleFile{
+			Name: strings.TrimPrefix(f.Name, prefix),
+			Open: func() (io.ReadCloser, error) { return zipFile.Open() },
+		})
+	}
+	return m, nil
+}
@@ -2,6 +2,7 @@ package resolve
 
 import (
 	"fmt"
+	"path"
 	"strings"
 )
 
@@ -16,9 +17,76 @@ func RepositoryModulePath(path string) string {
 	return fmt.Sprintf("https://%s", path)
 }
 
-// GetGithubOwnerAndRepo splits the repository into owner and repository name.
+// IsGithubModule return true when the module repository is hosted on GitHub.
+func IsGithubModule(path string) bool {
+	repository := RepositoryModulePath(path)
+	return strings.HasPrefix(repository, "https://github.com/") && strings.Count(repository, "/") >= 4
+}
+
+// RepositoryRoot return the repository the module is located in. Modules in subdirectories of the same GitHub repository
+// (eg. kubernetes staging modules) share the repository root.
+func RepositoryRoot(path string) string {
+	repository := RepositoryModulePath(path)
+	if !IsGithubModule(path) {
+		return repository
+	}
+	return strings.Join(strings.SplitN(repository, "/", 6)[:5], "/")
+}
+
+// RepositoryHost return the host of the module repository (eg. 'github.com' for 'k8s.io/api').
+func RepositoryHost(path string) string {
+	return strings.SplitN(strings.TrimPrefix(RepositoryModulePath(path), "https://"), "/", 2)[0]
+}
+
+// GetGithubOwnerAndRepo splits the repository (eg. 'https://github.corp.example.com/team/api') into owner and
+// repository name. Both are empty when the repository has no owner and name.
 func GetGithubOwnerAndRepo(r string) (string, string) {
-	r = strings.TrimPrefix(r, "https://github.com/")
-	parts := strings.Split(r, "/")
-	return parts[0], parts[1]
+	parts := strings.Split(strings.TrimPrefix(r, "https://"), "/")
+	if len(parts) < 3 {
+		return "", ""
+	}
+	return parts[1], parts[2]
+}
+
+// ModuleSubdirectory return the directory inside the Github repository where the module go.mod file is located.
+// The major version suffix (eg. '/v2') is ignored and the module is expected in the repository root in that case.
+func ModuleSubdirectory(path string) string {
+	parts := strings.Split(strings.TrimPrefix(RepositoryModulePath(path), "https://"), "/")
+	if len(parts) <= 3 {
+		return ""
+	}
+	parts = parts[3:]
+	if last := parts[len(parts)-1]; len(last) > 1 && last[0] == 'v' && strings.Trim(last[1:], "0123456789") == "" {
+		parts = parts[:len(parts)-1]
+	}
+	return strings.Join(parts, "/")
+}
+
+// MatchPrefixPatterns return true when any of the comma separated glob patterns (eg. GOPRIVATE) match a prefix of the
+// module path with the same number of path elements as the pattern.
+func MatchPrefixPatterns(patterns, modulePath string) bool {
+	for _, pattern := range strings.Split(patterns, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if len(pattern) == 0 {
+			continue
+		}
+		prefix, elements := modulePath, strings.Count(pattern, "/")
+		for i := 0; i < len(modulePath); i++ {
+			if modulePath[i] != '/' {
+				continue
+			}
+			if elements == 0 {
+				prefix = modulePath[:i]
+				break
+			}
+			elements--
+		}
+		if elements > 0 {
+			continue
+		}
+		if matched, _ := path.Match(pattern, prefix); matched {
+			return true
+		}
+	}
+	return false
 }
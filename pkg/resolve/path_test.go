@@ -0,0 +1,85 @@
+package resolve
+
+import (
+	"testing"
+)
+
+func TestModuleSubdirectory(t *testing.T) {
+	tests := []struct {
+		path   string
+		subdir string
+	}{
+		{path: "k8s.io/api", subdir: ""},
+		{path: "github.com/openshift/library-go", subdir: ""},
+		{path: "github.com/openshift/library-go/v2", subdir: ""},
+		{path: "github.com/openshift/api/tools", subdir: "tools"},
+		{path: "github.com/kubernetes/kubernetes/staging/src/k8s.io/api", subdir: "staging/src/k8s.io/api"},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			if subdir := ModuleSubdirectory(test.path); subdir != test.subdir {
+				t.Errorf("expected %q, got %q", test.subdir, subdir)
+			}
+		})
+	}
+}
+
+func TestIsGithubModule(t *testing.T) {
+	for path, expected := range map[string]bool{
+		"k8s.io/api":                      true,
+		"github.com/openshift/library-go": true,
+		"github.com/openshift":            false,
+		"golang.org/x/net":                false,
+	} {
+		if IsGithubModule(path) != expected {
+			t.Errorf("%s: expected %t", path, expected)
+		}
+	}
+}
+
+func TestRepositoryRoot(t *testing.T) {
+	for path, expected := range map[string]string{
+		"k8s.io/api":                     "https://github.com/kubernetes/api",
+		"github.com/openshift/api":       "https://github.com/openshift/api",
+		"github.com/openshift/api/tools": "https://github.com/openshift/api",
+		"github.com/kubernetes/kubernetes/staging/src/k8s.io/api": "https://github.com/kubernetes/kubernetes",
+		"golang.org/x/net": "https://golang.org/x/net",
+	} {
+		if root := RepositoryRoot(path); root != expected {
+			t.Errorf("%s: expected %q, got %q", path, expected, root)
+		}
+	}
+}
+
+func TestMatchPrefixPatterns(t *testing.T) {
+	tests := []struct {
+		patterns   string
+		modulePath string
+		expected   bool
+	}{
+		{patterns: "git.corp.example.com", modulePath: "git.corp.example.com/team/lib", expected: true},
+		{patterns: "github.com/myorg/*", modulePath: "github.com/myorg/lib/v2", expected: true},
+		{patterns: "github.com/myorg/*", modulePath: "github.com/myorg", expected: false},
+		{patterns: "*.corp.example.com,github.com/myorg", modulePath: "git.corp.example.com/lib", expected: true},
+		{patterns: "github.com/myorg", modulePath: "github.com/myorganization/lib", expected: false},
+		{patterns: "", modulePath: "github.com/myorg/lib", expected: false},
+	}
+	for _, test := range tests {
+		if matched := MatchPrefixPatterns(test.patterns, test.modulePath); matched != test.expected {
+			t.Errorf("%q %q: expected %t", test.patterns, test.modulePath, test.expected)
+		}
+	}
+}
+
+func TestGetGithubOwnerAndRepo(t *testing.T) {
+	for repository, expected := range map[string][2]string{
+		"https://github.com/openshift/api":                   {"openshift", "api"},
+		"https://github.corp.example.com/team/api/subdir":    {"team", "api"},
+		"https://github.corp.example.com/team":               {"", ""},
+		"https://github.com/kubernetes/kubernetes/staging/x": {"kubernetes", "kubernetes"},
+	} {
+		if owner, repo := GetGithubOwnerAndRepo(repository); owner != expected[0] || repo != expected[1] {
+			t.Errorf("%s: expected %s/%s, got %s/%s", repository, expected[0], expected[1], owner, repo)
+		}
+	}
+}
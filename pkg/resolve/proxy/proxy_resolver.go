@@ -0,0 +1,75 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mfojtik/goodmod/pkg/golang"
+	"github.com/mfojtik/goodmod/pkg/resolve"
+	"github.com/mfojtik/goodmod/pkg/resolve/gomod"
+	"github.com/mfojtik/goodmod/pkg/resolve/types"
+)
+
+type ProxyResolver struct {
+	proxyURL string
+}
+
+// NewProxyResolver return resolver that query the Go module proxy for the branch, tag or commit ('@v/<name>.info').
+// The proxy is the first URL in GOPROXY environment variable or proxy.golang.org when not set.
+func NewProxyResolver() resolve.ModulerResolver {
+	return &ProxyResolver{proxyURL: gomod.ProxyURL()}
+}
+
+// versionInfo is the proxy response to the '.info' query, the origin is only returned by recent proxies.
+type versionInfo struct {
+	Version string
+	Time    time.Time
+	Origin  *struct {
+		Hash string
+	}
+}
+
+func (p *ProxyResolver) Resolve(ctx context.Context, modulePath string, name string) (*types.Commit, error) {
+	escapedPath, err := golang.EscapePath(modulePath)
+	if err != nil {
+		return nil, err
+	}
+	escapedName, err := golang.EscapeVersion(name)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/%s/@v/%s.info", p.proxyURL, escapedPath, escapedName), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s: %s", req.URL, resp.Status, strings.TrimSpace(string(content)))
+	}
+	info := versionInfo{}
+	if err := json.Unmarshal(content, &info); err != nil {
+		return nil, fmt.Errorf("%s: unable to parse response: %v", req.URL, err)
+	}
+	// the abbreviated SHA from pseudo-version is enough to produce the pseudo-version again
+	sha, ok := golang.PseudoVersionCommit(info.Version)
+	if info.Origin != nil && len(info.Origin.Hash) > 0 {
+		sha, ok = info.Origin.Hash, true
+	}
+	if !ok {
+		return nil, fmt.Errorf("%s: proxy returned version %q without commit", req.URL, info.Version)
+	}
+	return &types.Commit{SHA: sha, Timestamp: info.Time}, nil
+}
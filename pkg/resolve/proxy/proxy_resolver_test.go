@@ -0,0 +1,53 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProxyResolver(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/github.com/openshift/api/@v/master.info":
+			fmt.Fprint(w, `{"Version": "v0.0.0-20191016115129-c07a134afb42", "Time": "2019-10-16T11:51:29Z"}`)
+		case "/k8s.io/api/@v/v0.16.2.info":
+			fmt.Fprint(w, `{"Version": "v0.16.2", "Time": "2019-10-15T19:18:26Z", "Origin": {"VCS": "git", "Hash": "35e52d86657a9a6a4ad19d1432e3a8a6a51af3e3"}}`)
+		case "/k8s.io/api/@v/v0.17.0.info":
+			fmt.Fprint(w, `{"Version": "v0.17.0", "Time": "2019-12-07T21:12:22Z"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		modulePath string
+		name       string
+		expected   string
+	}{
+		{modulePath: "github.com/openshift/api", name: "master", expected: "v0.0.0-20191016115129-c07a134afb42"},
+		{modulePath: "k8s.io/api", name: "v0.16.2", expected: "v0.0.0-20191015191826-35e52d86657a"},
+		{modulePath: "k8s.io/api", name: "v0.17.0"},
+		{modulePath: "k8s.io/api", name: "missing"},
+	}
+	r := &ProxyResolver{proxyURL: server.URL}
+	for _, test := range tests {
+		c, err := r.Resolve(context.TODO(), test.modulePath, test.name)
+		if len(test.expected) == 0 {
+			if err == nil {
+				t.Errorf("%s@%s: expected error, got %s", test.modulePath, test.name, c.String())
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s@%s: unexpected error: %v", test.modulePath, test.name, err)
+			continue
+		}
+		if c.String() != test.expected {
+			t.Errorf("%s@%s: expected %s, got %s", test.modulePath, test.name, test.expected, c.String())
+		}
+	}
+}
@@ -2,6 +2,9 @@ package resolve
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/mfojtik/goodmod/pkg/resolve/types"
 )
@@ -9,3 +12,58 @@ import (
 type ModulerResolver interface {
 	Resolve(ctx context.Context, modulePath string, name string) (*types.Commit, error)
 }
+
+// CommitStore is implemented by resolvers that store commits resolved by the resolvers following them in the chain.
+type CommitStore interface {
+	Store(modulePath string, name string, commit *types.Commit) error
+}
+
+// RefKind is the kind of the ref resolved to commit.
+type RefKind string
+
+const (
+	BranchRef RefKind = "branch"
+	TagRef    RefKind = "tag"
+	CommitRef RefKind = "commit"
+)
+
+// GoModFetcher fetch the go.mod file content of the module at given commit.
+type GoModFetcher interface {
+	Fetch(ctx context.Context, modulePath string, commit *types.Commit) ([]byte, error)
+}
+
+// ModuleFetcher fetch the content of the module version.
+type ModuleFetcher interface {
+	Fetch(ctx context.Context, modulePath string, version string) (*types.Module, error)
+}
+
+// Kinds of resolvers and fetchers, used as keys of per-resolver timeouts in config file.
+const (
+	GithubKind = "github"
+	GitKind    = "git"
+	ProxyKind  = "proxy"
+	CacheKind  = "cache"
+	ExecKind   = "exec"
+	LocalKind  = "local"
+)
+
+// Kind return the kind of resolver or fetcher derived from its type name (eg. 'github' for GithubTagResolver).
+func Kind(r interface{}) string {
+	name := fmt.Sprintf("%T", r)
+	name = strings.ToLower(name[strings.LastIndex(name, ".")+1:])
+	// github must be matched before git
+	for _, kind := range []string{GithubKind, GitKind, ProxyKind, CacheKind, ExecKind, LocalKind} {
+		if strings.HasPrefix(name, kind) {
+			return kind
+		}
+	}
+	return name
+}
+
+// WithTimeout return context canceled after timeout. When timeout is zero, the context is only canceled with parent.
+func WithTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
@@ -0,0 +1,37 @@
+package resolve
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type GithubTagResolver struct{}
+type GitTagResolver struct{}
+type ProxyGoModFetcher struct{}
+
+func TestKind(t *testing.T) {
+	for r, expected := range map[interface{}]string{&GithubTagResolver{}: GithubKind, &GitTagResolver{}: GitKind, ProxyGoModFetcher{}: ProxyKind} {
+		if kind := Kind(r); kind != expected {
+			t.Errorf("%T: expected %q, got %q", r, expected, kind)
+		}
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	ctx, cancel := WithTimeout(context.Background(), 0)
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("expected no deadline for zero timeout")
+	}
+	cancel()
+	if ctx.Err() != context.Canceled {
+		t.Errorf("expected canceled context, got %v", ctx.Err())
+	}
+
+	ctx, cancel = WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	<-ctx.Done()
+	if ctx.Err() != context.DeadlineExceeded {
+		t.Errorf("expected deadline exceeded, got %v", ctx.Err())
+	}
+}
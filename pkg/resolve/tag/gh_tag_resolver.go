@@ -2,24 +2,24 @@ package tag
 
 import (
 	"context"
-	"net/http"
-
-	"github.com/google/go-github/v28/github"
 
 	"github.com/mfojtik/goodmod/pkg/resolve"
 	"github.com/mfojtik/goodmod/pkg/resolve/types"
 )
 
 type GithubTagResolver struct {
-	oauthClient *http.Client
+	hosts *resolve.GithubHosts
 }
 
-func NewGithubTagResolver(oauthClient *http.Client) resolve.ModulerResolver {
-	return &GithubTagResolver{oauthClient: oauthClient}
+func NewGithubTagResolver(hosts *resolve.GithubHosts) resolve.ModulerResolver {
+	return &GithubTagResolver{hosts: hosts}
 }
 
 func (r *GithubTagResolver) Resolve(ctx context.Context, path string, tagName string) (*types.Commit, error) {
-	client := github.NewClient(r.oauthClient)
+	client, err := r.hosts.APIClient(path)
+	if err != nil {
+		return nil, err
+	}
 	owner, repo := resolve.GetGithubOwnerAndRepo(resolve.RepositoryModulePath(path))
 	tree, _, err := client.Git.GetTree(ctx, owner, repo, tagName, false)
 	if err != nil {
@@ -7,7 +7,7 @@ import (
 
 func TestGithubTagResolver(t *testing.T) {
 	r := &GithubTagResolver{}
-	c, err := r.Resolve(context.TODO(), "https://github.com/kubernetes/apiserver", "kubernetes-1.16.0")
+	c, err := r.Resolve(context.TODO(), "github.com/kubernetes/apiserver", "kubernetes-1.16.0")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -4,21 +4,20 @@ import (
 	"context"
 	"fmt"
 
-	"gopkg.in/src-d/go-git.v4"
 	"gopkg.in/src-d/go-git.v4/plumbing"
-	"gopkg.in/src-d/go-git.v4/storage/memory"
 
 	"github.com/mfojtik/goodmod/pkg/resolve"
 	"github.com/mfojtik/goodmod/pkg/resolve/types"
 )
 
 type GitTagResolver struct {
+	auth *resolve.GitAuth
 }
 
 func (g *GitTagResolver) Resolve(ctx context.Context, modulePath string, name string) (*types.Commit, error) {
-	repository, err := git.Clone(memory.NewStorage(), nil, &git.CloneOptions{URL: resolve.RepositoryModulePath(modulePath)})
+	repository, err := resolve.Clone(ctx, modulePath, g.auth)
 	if err != nil {
-		return nil, fmt.Errorf("failed to clone githelper repository %s: %v", resolve.RepositoryModulePath(modulePath), err)
+		return nil, err
 	}
 
 	ref, err := repository.Storer.Reference(plumbing.NewTagReferenceName(name))
@@ -40,6 +39,6 @@ func (g *GitTagResolver) Resolve(ctx context.Context, modulePath string, name st
 	}, nil
 }
 
-func NewGitTagResolver() resolve.ModulerResolver {
-	return &GitTagResolver{}
+func NewGitTagResolver(auth *resolve.GitAuth) resolve.ModulerResolver {
+	return &GitTagResolver{auth: auth}
 }
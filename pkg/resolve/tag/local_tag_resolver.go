@@ -0,0 +1,32 @@
+package tag
+
+import (
+	"context"
+
+	"github.com/mfojtik/goodmod/pkg/resolve"
+	"github.com/mfojtik/goodmod/pkg/resolve/types"
+)
+
+type LocalTagResolver struct {
+	dirs []string
+}
+
+// NewLocalTagResolver return resolver that find the tag in the local clone of the module repository.
+func NewLocalTagResolver(dirs []string) resolve.ModulerResolver {
+	return &LocalTagResolver{dirs: dirs}
+}
+
+func (l *LocalTagResolver) Resolve(ctx context.Context, modulePath string, name string) (*types.Commit, error) {
+	repository, err := resolve.OpenLocal(l.dirs, modulePath)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := resolve.LocalTag(repository, name)
+	if err != nil {
+		return nil, err
+	}
+	return &types.Commit{
+		SHA:       commit.Hash.String(),
+		Timestamp: commit.Committer.When,
+	}, nil
+}
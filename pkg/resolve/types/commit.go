@@ -6,8 +6,8 @@ import (
 )
 
 type Commit struct {
-	SHA       string
-	Timestamp time.Time
+	SHA       string    `json:"sha"`
+	Timestamp time.Time `json:"timestamp"`
 }
 
 func (c Commit) String() string {
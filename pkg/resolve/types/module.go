@@ -0,0 +1,21 @@
+package types
+
+import (
+	"io"
+)
+
+// ModuleFile is a single file in module zip.
+type ModuleFile struct {
+	// Name is the file path relative to module root directory
+	Name string
+	Open func() (io.ReadCloser, error)
+}
+
+// Module is the content of a module version as it is stored in module zip.
+type Module struct {
+	Path    string
+	Version string
+
+	GoMod []byte
+	Files []ModuleFile
+}